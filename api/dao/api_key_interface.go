@@ -0,0 +1,17 @@
+package dao
+
+import (
+	"GoGin/internal/model"
+	"time"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *model.APIKey) error
+	// GetAPIKeyByHash 认证时按哈希查找，可能走缓存
+	GetAPIKeyByHash(keyHash string) (*model.APIKey, error)
+	ListUserAPIKeys(userID int) ([]model.APIKey, error)
+	RevokeAPIKey(userID, keyID int) error
+	TouchAPIKey(keyID int, usedAt time.Time) error
+	// DeleteUserAPIKeys 彻底删除账号时清除该用户的全部 Key
+	DeleteUserAPIKeys(userID int) error
+}
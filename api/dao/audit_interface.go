@@ -0,0 +1,9 @@
+package dao
+
+import "GoGin/internal/model"
+
+// AuditRepository 审计日志只允许追加和查询
+type AuditRepository interface {
+	AppendAudit(entry *model.AuditEntry) error
+	ListAudit(filter model.AuditFilter) ([]model.AuditEntry, error)
+}
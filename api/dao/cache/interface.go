@@ -1,13 +1,24 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss 键不存在；其余错误视为缓存不可用
+var ErrCacheMiss = errors.New("cache miss")
 
 type Cache interface {
 	Set(key string, value interface{}, expiration time.Duration) error
 	Get(key string, dest interface{}) error
+	// Incr 原子自增，键首次创建时设置过期时间
+	Incr(key string, expiration time.Duration) (int64, error)
 	RandExp(base time.Duration) time.Duration
-	Lock(key string, expire time.Duration) (bool, error)
-	Unlock(key string) error
+	// TryLock 非阻塞获取锁，已被占用时返回 ErrLockNotAcquired；持有期间自动续期，用完需 Release
+	TryLock(key string, ttl time.Duration) (*Lock, error)
+	// AcquireLock 按退避重试直到获取成功或 ctx 结束
+	AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error)
 	Clean(keys ...string) error
 	Exists(key string) bool
 }
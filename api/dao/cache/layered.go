@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// invalidationChannel 各实例通过该频道通知其他实例删除本地缓存
+const invalidationChannel = "cache:invalidate"
+
+// LayeredCache 两级缓存：进程内 L1 在前，Redis L2 在后。
+// 读取先查 L1，未命中再查 Redis 并回填 L1；写入和删除同时作用于两级，并通过 Redis pub/sub 通知其他实例删除 L1。
+// 计数和锁必须全局一致，只走 Redis。pub/sub 消息丢失时，其他实例的 L1 最多保留 l1TTL
+type LayeredCache struct {
+	l1       *MemoryCache
+	l2       *RedisClient
+	l1TTL    time.Duration
+	instance string // 本实例标识，收到自己发出的通知时忽略
+}
+
+type invalidation struct {
+	Instance string   `json:"instance"`
+	Keys     []string `json:"keys"`
+}
+
+func NewLayeredCache(l2 *RedisClient, l1TTL time.Duration, l1MaxEntries int) Cache {
+	lc := &LayeredCache{
+		l1:       NewMemoryCache(l1MaxEntries).(*MemoryCache),
+		l2:       l2,
+		l1TTL:    l1TTL,
+		instance: randomToken(8),
+	}
+	go lc.subscribe()
+	return lc
+}
+
+func (lc *LayeredCache) Set(key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := lc.l2.client.Set(lc.l2.ctx, key, data, expiration).Err(); err != nil {
+		return err
+	}
+
+	// 其他实例的 L1 中可能是旧值
+	lc.l1.setBytes(key, data, lc.localTTL(expiration))
+	lc.publish(key)
+	return nil
+}
+
+func (lc *LayeredCache) Get(key string, dest interface{}) error {
+	if data, ok := lc.l1.getBytes(key); ok {
+		return json.Unmarshal(data, dest)
+	}
+
+	data, ttl, err := lc.l2.getWithTTL(key)
+	if err != nil {
+		return err
+	}
+	lc.l1.setBytes(key, data, lc.localTTL(ttl))
+	return json.Unmarshal(data, dest)
+}
+
+func (lc *LayeredCache) Incr(key string, expiration time.Duration) (int64, error) {
+	return lc.l2.Incr(key, expiration)
+}
+
+// RandExp 防止缓存雪崩
+func (lc *LayeredCache) RandExp(base time.Duration) time.Duration {
+	return randExp(base)
+}
+
+// TryLock 获取分布式锁
+func (lc *LayeredCache) TryLock(key string, ttl time.Duration) (*Lock, error) {
+	return lc.l2.TryLock(key, ttl)
+}
+
+// AcquireLock 阻塞获取分布式锁，直到成功或 ctx 结束
+func (lc *LayeredCache) AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return lc.l2.AcquireLock(ctx, key, ttl)
+}
+
+// Clean 删除两级缓存并通知其他实例
+func (lc *LayeredCache) Clean(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_ = lc.l1.Clean(keys...)
+	if err := lc.l2.Clean(keys...); err != nil {
+		return err
+	}
+	lc.publish(keys...)
+	return nil
+}
+
+func (lc *LayeredCache) Exists(key string) bool {
+	if _, ok := lc.l1.getBytes(key); ok {
+		return true
+	}
+	return lc.l2.Exists(key)
+}
+
+// localTTL L1 的过期时间不超过 l1TTL，也不超过 Redis 中的剩余时间
+func (lc *LayeredCache) localTTL(remaining time.Duration) time.Duration {
+	if remaining > 0 && remaining < lc.l1TTL {
+		return remaining
+	}
+	return lc.l1TTL
+}
+
+func (lc *LayeredCache) publish(keys ...string) {
+	data, err := json.Marshal(invalidation{Instance: lc.instance, Keys: keys})
+	if err != nil {
+		return
+	}
+	if err := lc.l2.client.Publish(lc.l2.ctx, invalidationChannel, data).Err(); err != nil {
+		log.Println("cache invalidation publish failed:", err)
+	}
+}
+
+// subscribe 接收其他实例的删除通知，断线后由 go-redis 自动重连
+func (lc *LayeredCache) subscribe() {
+	pubsub := lc.l2.client.Subscribe(lc.l2.ctx, invalidationChannel)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		var inv invalidation
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			continue
+		}
+		if inv.Instance == lc.instance {
+			continue
+		}
+		_ = lc.l1.Clean(inv.Keys...)
+	}
+}
@@ -0,0 +1,285 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis 只实现 LayeredCache 用到的命令（RESP2），用于在没有 Redis 的环境中测试多实例失效通知
+type fakeRedis struct {
+	ln net.Listener
+
+	mu   sync.Mutex
+	data map[string]fakeValue
+	subs map[string][]*fakeConn
+}
+
+type fakeValue struct {
+	value     string
+	expiresAt time.Time // 零值表示不过期
+}
+
+type fakeConn struct {
+	conn net.Conn
+	mu   sync.Mutex
+	w    *bufio.Writer
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeRedis{ln: ln, data: make(map[string]fakeValue), subs: make(map[string][]*fakeConn)}
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+func (s *fakeRedis) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// subscribers 频道当前的订阅连接数
+func (s *fakeRedis) subscribers(channel string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs[channel])
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(&fakeConn{conn: conn, w: bufio.NewWriter(conn)})
+	}
+}
+
+func (s *fakeRedis) handle(c *fakeConn) {
+	defer c.conn.Close()
+	r := bufio.NewReader(c.conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.exec(c, args)
+	}
+}
+
+func (s *fakeRedis) exec(c *fakeConn, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		c.reply("+PONG\r\n")
+	case "GET":
+		if v, ok := s.lookup(args[1]); ok {
+			c.reply(bulk(v.value))
+		} else {
+			c.reply("$-1\r\n")
+		}
+	case "SET":
+		v := fakeValue{value: args[2]}
+		for i := 3; i+1 < len(args); i += 2 {
+			n, _ := strconv.Atoi(args[i+1])
+			switch strings.ToUpper(args[i]) {
+			case "EX":
+				v.expiresAt = time.Now().Add(time.Duration(n) * time.Second)
+			case "PX":
+				v.expiresAt = time.Now().Add(time.Duration(n) * time.Millisecond)
+			}
+		}
+		s.data[args[1]] = v
+		c.reply("+OK\r\n")
+	case "PTTL":
+		v, ok := s.lookup(args[1])
+		switch {
+		case !ok:
+			c.reply(":-2\r\n")
+		case v.expiresAt.IsZero():
+			c.reply(":-1\r\n")
+		default:
+			c.reply(fmt.Sprintf(":%d\r\n", time.Until(v.expiresAt).Milliseconds()))
+		}
+	case "DEL", "EXISTS":
+		n := 0
+		for _, key := range args[1:] {
+			if _, ok := s.lookup(key); ok {
+				n++
+				if strings.ToUpper(args[0]) == "DEL" {
+					delete(s.data, key)
+				}
+			}
+		}
+		c.reply(fmt.Sprintf(":%d\r\n", n))
+	case "SUBSCRIBE":
+		for i, channel := range args[1:] {
+			s.subs[channel] = append(s.subs[channel], c)
+			c.reply("*3\r\n" + bulk("subscribe") + bulk(channel) + fmt.Sprintf(":%d\r\n", i+1))
+		}
+	case "PUBLISH":
+		subs := s.subs[args[1]]
+		for _, sub := range subs {
+			sub.reply("*3\r\n" + bulk("message") + bulk(args[1]) + bulk(args[2]))
+		}
+		c.reply(fmt.Sprintf(":%d\r\n", len(subs)))
+	default:
+		// HELLO、CLIENT SETINFO 等握手命令按旧版本 Redis 处理
+		c.reply("-ERR unknown command '" + args[0] + "'\r\n")
+	}
+}
+
+func (s *fakeRedis) lookup(key string) (fakeValue, bool) {
+	v, ok := s.data[key]
+	if ok && !v.expiresAt.IsZero() && !time.Now().Before(v.expiresAt) {
+		delete(s.data, key)
+		return fakeValue{}, false
+	}
+	return v, ok
+}
+
+func (c *fakeConn) reply(resp string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = c.w.WriteString(resp)
+	_ = c.w.Flush()
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("inline commands not supported")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || n < 1 {
+		return nil, errors.New("bad array header")
+	}
+	args := make([]string, n)
+	for i := range args {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, errors.New("bad bulk header")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func newTestLayeredCache(t *testing.T, addr string, l1TTL time.Duration) *LayeredCache {
+	t.Helper()
+	l2 := NewRedisClient(addr, "", 0).(*RedisClient)
+	t.Cleanup(func() { _ = l2.client.Close() })
+	return NewLayeredCache(l2, l1TTL, 100).(*LayeredCache)
+}
+
+// eventually 在 1 秒内反复检查条件
+func eventually(t *testing.T, msg string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLayeredCacheInvalidatesOtherInstances(t *testing.T) {
+	srv := newFakeRedis(t)
+	a := newTestLayeredCache(t, srv.Addr(), time.Minute)
+	b := newTestLayeredCache(t, srv.Addr(), time.Minute)
+	eventually(t, "instances did not subscribe", func() bool { return srv.subscribers(invalidationChannel) == 2 })
+
+	if err := a.Set("course:1", "v1", time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	var got string
+	if err := b.Get("course:1", &got); err != nil || got != "v1" {
+		t.Fatalf("b.Get = %q, %v, want v1", got, err)
+	}
+	// b 的 L1 已回填，直接改 Redis 不会被 b 看到
+	srv.mu.Lock()
+	srv.data["course:1"] = fakeValue{value: `"stale-check"`}
+	srv.mu.Unlock()
+	if err := b.Get("course:1", &got); err != nil || got != "v1" {
+		t.Fatalf("b.Get from L1 = %q, %v, want v1", got, err)
+	}
+
+	// a 写入后通知 b 删除 L1
+	if err := a.Set("course:1", "v2", time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	eventually(t, "b still serves the old value after a.Set", func() bool {
+		var v string
+		return b.Get("course:1", &v) == nil && v == "v2"
+	})
+
+	// 删除同样通知其他实例
+	if err := a.Clean("course:1"); err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+	eventually(t, "b still serves the value after a.Clean", func() bool {
+		var v string
+		return errors.Is(b.Get("course:1", &v), ErrCacheMiss)
+	})
+	if b.Exists("course:1") {
+		t.Fatal("Exists after Clean = true")
+	}
+}
+
+// L1 的过期时间不超过 Redis 中的剩余时间
+func TestLayeredCacheLocalTTL(t *testing.T) {
+	lc := &LayeredCache{l1TTL: 30 * time.Second}
+	tests := []struct {
+		remaining time.Duration
+		want      time.Duration
+	}{
+		{0, 30 * time.Second},
+		{time.Second, time.Second},
+		{time.Minute, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := lc.localTTL(tt.remaining); got != tt.want {
+			t.Errorf("localTTL(%v) = %v, want %v", tt.remaining, got, tt.want)
+		}
+	}
+
+	srv := newFakeRedis(t)
+	c := newTestLayeredCache(t, srv.Addr(), time.Minute)
+	if err := c.Set("short", "v", 50*time.Millisecond); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	time.Sleep(80 * time.Millisecond)
+	var v string
+	if err := c.Get("short", &v); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Get after Redis TTL = %q, %v, want ErrCacheMiss", v, err)
+	}
+}
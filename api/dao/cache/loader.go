@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound 加载函数返回该错误表示数据不存在，结果会按 NegativeTTL 写入负缓存
+var ErrNotFound = errors.New("not found")
+
+// LoadOptions TTL 与 NegativeTTL 均为基础时间，写入时经 RandExp 抖动
+type LoadOptions struct {
+	TTL time.Duration
+	// NegativeTTL 不存在时的缓存时间，防止缓存穿透；0 表示不缓存
+	NegativeTTL time.Duration
+}
+
+// loaderEntry 缓存中保存的包装：NotFound 为负缓存标记，真实值即使是零值也不会与之混淆
+type loaderEntry[T any] struct {
+	Value    T    `json:"v"`
+	NotFound bool `json:"nf,omitempty"`
+}
+
+// loads 进程内合并同一个键的并发加载
+var loads flightGroup
+
+// GetOrLoad 旁路缓存：先读缓存，未命中时调用 load 并写回。
+// 同一进程内同一个键的并发未命中只会执行一次 load，所有调用方共享同一个结果，不应修改其中的引用类型字段。
+// load 返回 ErrNotFound 时写入负缓存，之后的读取直接返回 ErrNotFound；其他错误不缓存。
+// c 为 nil 时直接加载。同一个键只能对应一种 T
+func GetOrLoad[T any](c Cache, key string, opts LoadOptions, load func() (T, error)) (T, error) {
+	var zero T
+	if c != nil {
+		var entry loaderEntry[T]
+		if err := c.Get(key, &entry); err == nil {
+			if entry.NotFound {
+				return zero, ErrNotFound
+			}
+			return entry.Value, nil
+		}
+	}
+
+	v, err := loads.Do(key, func() (interface{}, error) {
+		value, err := load()
+		if c != nil {
+			switch {
+			case err == nil:
+				_ = c.Set(key, loaderEntry[T]{Value: value}, c.RandExp(opts.TTL))
+			case errors.Is(err, ErrNotFound) && opts.NegativeTTL > 0:
+				_ = c.Set(key, loaderEntry[T]{NotFound: true}, c.RandExp(opts.NegativeTTL))
+			}
+		}
+		return &value, err
+	})
+	if err != nil {
+		return zero, err
+	}
+	value, ok := v.(*T)
+	if !ok {
+		return zero, fmt.Errorf("cache key %s loaded as %T", key, v)
+	}
+	return *value, nil
+}
+
+// Store 以 GetOrLoad 的格式写入缓存，用于写操作后直接更新缓存
+func Store[T any](c Cache, key string, value T, ttl time.Duration) error {
+	if c == nil {
+		return nil
+	}
+	return c.Set(key, loaderEntry[T]{Value: value}, c.RandExp(ttl))
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"GoGin/internal/model"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 缓存以 JSON 保存，model.User 的密码哈希不参与序列化；凭据校验必须绕过缓存读取
+func TestGetOrLoadUserDropsPassword(t *testing.T) {
+	c := NewMemoryCache(100)
+	opts := LoadOptions{TTL: time.Minute}
+	loads := 0
+	load := func() (model.User, error) {
+		loads++
+		return model.User{UserID: 1, Username: "alice", Password: "hash"}, nil
+	}
+
+	first, err := GetOrLoad(c, "user:username:alice", opts, load)
+	if err != nil {
+		t.Fatalf("first GetOrLoad: %v", err)
+	}
+	if first.Password != "hash" {
+		t.Fatalf("loaded Password = %q, want hash", first.Password)
+	}
+
+	second, err := GetOrLoad(c, "user:username:alice", opts, load)
+	if err != nil {
+		t.Fatalf("second GetOrLoad: %v", err)
+	}
+	if loads != 1 {
+		t.Fatalf("load called %d times, want 1", loads)
+	}
+	if second.Username != "alice" || second.Password != "" {
+		t.Fatalf("cached user = %+v, want username kept and password dropped", second)
+	}
+}
+
+func TestGetOrLoadNotFound(t *testing.T) {
+	c := NewMemoryCache(100)
+	opts := LoadOptions{TTL: time.Minute, NegativeTTL: time.Minute}
+	loads := 0
+	load := func() (model.User, error) {
+		loads++
+		return model.User{}, ErrNotFound
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := GetOrLoad(c, "user:username:nobody", opts, load); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("GetOrLoad err = %v, want ErrNotFound", err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("load called %d times, want 1", loads)
+	}
+}
+
+// 零值也是合法结果，不能与负缓存混淆
+func TestGetOrLoadZeroValue(t *testing.T) {
+	c := NewMemoryCache(100)
+	opts := LoadOptions{TTL: time.Minute, NegativeTTL: time.Minute}
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return 0, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, err := GetOrLoad(c, "count", opts, load); err != nil || v != 0 {
+			t.Fatalf("GetOrLoad = %d, %v", v, err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("load called %d times, want 1", loads)
+	}
+}
+
+func TestGetOrLoadErrorNotCached(t *testing.T) {
+	c := NewMemoryCache(100)
+	opts := LoadOptions{TTL: time.Minute, NegativeTTL: time.Minute}
+	loadErr := errors.New("db down")
+
+	if _, err := GetOrLoad(c, "k", opts, func() (string, error) { return "", loadErr }); !errors.Is(err, loadErr) {
+		t.Fatalf("GetOrLoad err = %v, want %v", err, loadErr)
+	}
+	v, err := GetOrLoad(c, "k", opts, func() (string, error) { return "ok", nil })
+	if err != nil || v != "ok" {
+		t.Fatalf("GetOrLoad after error = %q, %v", v, err)
+	}
+}
+
+func TestFlightGroupCoalesces(t *testing.T) {
+	var g flightGroup
+	var calls int32
+	release := make(chan struct{})
+
+	const callers = 10
+	var ready, done sync.WaitGroup
+	ready.Add(callers)
+	done.Add(callers)
+	results := make([]interface{}, callers)
+	for i := 0; i < callers; i++ {
+		go func(i int) {
+			defer done.Done()
+			ready.Done()
+			results[i], _ = g.Do("k", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return "v", nil
+			})
+		}(i)
+	}
+	ready.Wait()
+	// 等待其余调用进入 Do 并开始等待
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("fn called %d times, want 1", n)
+	}
+	for i, v := range results {
+		if v != "v" {
+			t.Fatalf("result[%d] = %v, want v", i, v)
+		}
+	}
+
+	// 完成后同一个键可以再次加载
+	if _, err := g.Do("k", func() (interface{}, error) { atomic.AddInt32(&calls, 1); return "v", nil }); err != nil {
+		t.Fatalf("second Do: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("fn called %d times after second Do, want 2", n)
+	}
+}
+
+// fn panic 时等待者得到错误，panic 继续传给执行 fn 的调用方
+func TestFlightGroupPanic(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		_, _ = g.Do("k", func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	type result struct {
+		v   interface{}
+		err error
+	}
+	waiter := make(chan result, 1)
+	go func() {
+		v, err := g.Do("k", func() (interface{}, error) { return "v", nil })
+		waiter <- result{v, err}
+	}()
+	// 等待第二个调用进入 Do 并开始等待
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if r := <-panicked; r != "boom" {
+		t.Fatalf("recovered %v, want boom", r)
+	}
+	if got := <-waiter; got.err == nil || got.v != nil {
+		t.Fatalf("waiter got %v, %v, want the panic error", got.v, got.err)
+	}
+}
+
+// 同一个键被不同类型加载时返回错误而不是零值
+func TestGetOrLoadTypeMismatch(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = GetOrLoad[string](nil, "mismatch", LoadOptions{}, func() (string, error) {
+			close(started)
+			<-release
+			return "v", nil
+		})
+	}()
+	<-started
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := GetOrLoad[int](nil, "mismatch", LoadOptions{}, func() (int, error) { return 1, nil })
+		errs <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if err := <-errs; err == nil {
+		t.Fatal("GetOrLoad[int] on a string key returned no error")
+	}
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	mathrand "math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLockNotAcquired 锁已被其他持有者占用
+	ErrLockNotAcquired = errors.New("lock not acquired")
+	// ErrLockNotHeld 锁已过期或被其他持有者获取，释放或续期失败
+	ErrLockNotHeld = errors.New("lock not held")
+)
+
+const (
+	lockMinBackoff = 10 * time.Millisecond
+	lockMaxBackoff = 500 * time.Millisecond
+)
+
+// lockBackend 锁的存储，键已带 "lock:" 前缀，所有操作都需比较持有者令牌
+type lockBackend interface {
+	// obtainLock 键不存在时写入 owner 并返回新的 fencing token，被占用时返回 0
+	obtainLock(key, owner string, ttl time.Duration) (int64, error)
+	// releaseLock 仅当持有者为 owner 时删除
+	releaseLock(key, owner string) (bool, error)
+	// extendLock 仅当持有者为 owner 时重置过期时间
+	extendLock(key, owner string, ttl time.Duration) (bool, error)
+}
+
+// Lock 已获取的锁。持有期间每 ttl/3 自动续期，续期失败时 Lost 关闭，
+// 此时锁可能已被他人获取，调用方应放弃后续写入。
+// Fence 为单调递增的 fencing token，下游存储可拒绝携带更小 token 的写入
+type Lock struct {
+	backend lockBackend
+	key     string
+	owner   string
+	fence   int64
+	ttl     time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+	lost chan struct{}
+	once sync.Once
+}
+
+func (l *Lock) Fence() int64 {
+	return l.fence
+}
+
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release 停止续期并释放锁，锁已不属于自己时返回 ErrLockNotHeld
+func (l *Lock) Release() error {
+	released := false
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		released = true
+	})
+	if !released {
+		return nil
+	}
+
+	ok, err := l.backend.releaseLock(l.key, l.owner)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// renew 定期续期直到 Release 或续期失败
+func (l *Lock) renew() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ok, err := l.backend.extendLock(l.key, l.owner, l.ttl)
+			if err != nil || !ok {
+				log.Printf("lock %s lost: renew failed", l.key)
+				close(l.lost)
+				return
+			}
+		}
+	}
+}
+
+// tryLock 尝试一次，被占用时返回 ErrLockNotAcquired
+func tryLock(backend lockBackend, key string, ttl time.Duration) (*Lock, error) {
+	if ttl < 3*time.Millisecond {
+		return nil, errors.New("lock ttl too short")
+	}
+	owner := randomToken(16)
+	lockKey := "lock:" + key
+	fence, err := backend.obtainLock(lockKey, owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if fence == 0 {
+		return nil, ErrLockNotAcquired
+	}
+
+	l := &Lock{
+		backend: backend,
+		key:     lockKey,
+		owner:   owner,
+		fence:   fence,
+		ttl:     ttl,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		lost:    make(chan struct{}),
+	}
+	go l.renew()
+	return l, nil
+}
+
+// acquireLock 按指数退避重试，直到获取成功或 ctx 结束
+func acquireLock(ctx context.Context, backend lockBackend, key string, ttl time.Duration) (*Lock, error) {
+	backoff := lockMinBackoff
+	for {
+		l, err := tryLock(backend, key, ttl)
+		if !errors.Is(err, ErrLockNotAcquired) {
+			return l, err
+		}
+
+		// 随机等待 [backoff/2, backoff)，避免多个等待者同时重试
+		wait := backoff/2 + time.Duration(mathrand.Int63n(int64(backoff/2)))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > lockMaxBackoff {
+			backoff = lockMaxBackoff
+		}
+	}
+}
+
+func randomToken(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTryLockExclusiveAndFence(t *testing.T) {
+	c := NewMemoryCache(100)
+
+	first, err := c.TryLock("job", time.Second)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	if _, err := c.TryLock("job", time.Second); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("second TryLock err = %v, want ErrLockNotAcquired", err)
+	}
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	// 重复释放是空操作
+	if err := first.Release(); err != nil {
+		t.Fatalf("second Release: %v", err)
+	}
+
+	second, err := c.TryLock("job", time.Second)
+	if err != nil {
+		t.Fatalf("TryLock after release: %v", err)
+	}
+	defer second.Release()
+	if second.Fence() <= first.Fence() {
+		t.Fatalf("fence = %d, want greater than %d", second.Fence(), first.Fence())
+	}
+}
+
+// 持有期间自动续期，超过 ttl 后锁仍然有效
+func TestLockRenewal(t *testing.T) {
+	c := NewMemoryCache(100)
+	l, err := c.TryLock("job", 30*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := c.TryLock("job", time.Second); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("TryLock while renewed err = %v, want ErrLockNotAcquired", err)
+	}
+	select {
+	case <-l.Lost():
+		t.Fatal("lock reported lost while renewing")
+	default:
+	}
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+}
+
+// 锁被他人取走后续期失败：Lost 关闭，Release 返回 ErrLockNotHeld，且不会删除他人的锁
+func TestLockLost(t *testing.T) {
+	c := NewMemoryCache(100)
+	l, err := c.TryLock("job", 30*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	mc := c.(*MemoryCache)
+	mc.mu.Lock()
+	mc.locks["lock:job"] = memoryLock{owner: "someone-else", until: time.Now().Add(time.Minute)}
+	mc.mu.Unlock()
+
+	select {
+	case <-l.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lost not closed after renew failed")
+	}
+	if err := l.Release(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Release err = %v, want ErrLockNotHeld", err)
+	}
+	if _, err := c.TryLock("job", time.Second); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("other owner's lock was released, TryLock err = %v", err)
+	}
+}
+
+func TestAcquireLockWaitsForRelease(t *testing.T) {
+	c := NewMemoryCache(100)
+	held, err := c.TryLock("job", time.Second)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.AcquireLock(ctx, "job", time.Second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("AcquireLock err = %v, want context.DeadlineExceeded", err)
+	}
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = held.Release()
+	}()
+	l, err := c.AcquireLock(context.Background(), "job", time.Second)
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"GoGin/api/dao"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type cacheLoginAttemptRepo struct {
+	cache Cache
+}
+
+func NewCacheLoginAttemptRepo(cache Cache) dao.LoginAttemptRepository {
+	return &cacheLoginAttemptRepo{
+		cache: cache,
+	}
+}
+
+func (repo *cacheLoginAttemptRepo) RecordFailure(key string, window time.Duration) (int64, error) {
+	if repo.cache == nil {
+		return 0, errors.New("cache unavailable")
+	}
+	return repo.cache.Incr(fmt.Sprintf("login:fail:%s", key), window)
+}
+
+func (repo *cacheLoginAttemptRepo) Block(key string, d time.Duration) error {
+	if repo.cache == nil {
+		return errors.New("cache unavailable")
+	}
+	// 保存解封时间，便于返回剩余等待时间
+	return repo.cache.Set(fmt.Sprintf("login:block:%s", key), time.Now().Add(d).Unix(), d)
+}
+
+func (repo *cacheLoginAttemptRepo) BlockedFor(key string) (time.Duration, error) {
+	if repo.cache == nil {
+		return 0, errors.New("cache unavailable")
+	}
+	var until int64
+	err := repo.cache.Get(fmt.Sprintf("login:block:%s", key), &until)
+	if errors.Is(err, ErrCacheMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	remaining := time.Until(time.Unix(until, 0))
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+func (repo *cacheLoginAttemptRepo) Reset(key string) error {
+	if repo.cache == nil {
+		return errors.New("cache unavailable")
+	}
+	return repo.cache.Clean(fmt.Sprintf("login:fail:%s", key), fmt.Sprintf("login:block:%s", key))
+}
@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryCacheEntries 未配置容量时内存缓存最多保存的键数
+const DefaultMemoryCacheEntries = 10000
+
+// MemoryCache 进程内缓存：带过期时间、容量满时淘汰最久未使用的键，锁只在本进程内有效。
+// 值与 Redis 一样以 JSON 保存，Get 得到的是副本
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int // 0 表示不限容量，只按过期时间删除
+	sweepAt    int // 不限容量时键数达到该值清理一次过期条目
+	entries    map[string]*list.Element
+	lru        *list.List // 队头为最近使用
+	locks      map[string]memoryLock
+	fence      int64
+}
+
+type memoryLock struct {
+	owner string
+	until time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // 零值表示不过期
+}
+
+func NewMemoryCache(maxEntries int) Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		locks:      make(map[string]memoryLock),
+	}
+}
+
+// NewStateMemoryCache 保存令牌吊销、登录失败计数等安全状态的进程内存储：不按容量淘汰，条目只在过期后删除，
+// 避免大量普通缓存写入挤掉吊销记录。不要与仓库数据缓存共用
+func NewStateMemoryCache() Cache {
+	return &MemoryCache{
+		sweepAt: DefaultMemoryCacheEntries,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		locks:   make(map[string]memoryLock),
+	}
+}
+
+func (mc *MemoryCache) Set(key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.set(key, data, expiration)
+	return nil
+}
+
+func (mc *MemoryCache) Get(key string, dest interface{}) error {
+	mc.mu.Lock()
+	entry := mc.get(key)
+	var data []byte
+	if entry != nil {
+		data = entry.value
+	}
+	mc.mu.Unlock()
+
+	if entry == nil {
+		return ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (mc *MemoryCache) Incr(key string, expiration time.Duration) (int64, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	// 与 Redis 脚本一致：只有首次创建时设置过期时间
+	entry := mc.get(key)
+	if entry == nil {
+		mc.set(key, []byte("1"), expiration)
+		return 1, nil
+	}
+	n, err := strconv.ParseInt(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	n++
+	entry.value = []byte(strconv.FormatInt(n, 10))
+	return n, nil
+}
+
+// RandExp 防止缓存雪崩
+func (mc *MemoryCache) RandExp(base time.Duration) time.Duration {
+	return randExp(base)
+}
+
+// TryLock 获取本进程内的锁，多实例部署时应使用 Redis
+func (mc *MemoryCache) TryLock(key string, ttl time.Duration) (*Lock, error) {
+	return tryLock(mc, key, ttl)
+}
+
+// AcquireLock 阻塞获取本进程内的锁，直到成功或 ctx 结束
+func (mc *MemoryCache) AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return acquireLock(ctx, mc, key, ttl)
+}
+
+func (mc *MemoryCache) obtainLock(key, owner string, ttl time.Duration) (int64, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if l, ok := mc.locks[key]; ok && time.Now().Before(l.until) {
+		return 0, nil
+	}
+	mc.fence++
+	mc.locks[key] = memoryLock{owner: owner, until: time.Now().Add(ttl)}
+	return mc.fence, nil
+}
+
+func (mc *MemoryCache) releaseLock(key, owner string) (bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	l, ok := mc.locks[key]
+	if !ok || l.owner != owner || !time.Now().Before(l.until) {
+		return false, nil
+	}
+	delete(mc.locks, key)
+	return true, nil
+}
+
+func (mc *MemoryCache) extendLock(key, owner string, ttl time.Duration) (bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	l, ok := mc.locks[key]
+	if !ok || l.owner != owner || !time.Now().Before(l.until) {
+		return false, nil
+	}
+	mc.locks[key] = memoryLock{owner: owner, until: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// Clean 删除缓存
+func (mc *MemoryCache) Clean(keys ...string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := mc.entries[key]; ok {
+			mc.remove(elem)
+		}
+	}
+	return nil
+}
+
+func (mc *MemoryCache) Exists(key string) bool {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.get(key) != nil
+}
+
+// getBytes 读取原始 JSON，供多级缓存使用
+func (mc *MemoryCache) getBytes(key string) ([]byte, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	entry := mc.get(key)
+	if entry == nil {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// setBytes 写入原始 JSON，供多级缓存使用
+func (mc *MemoryCache) setBytes(key string, data []byte, expiration time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.set(key, data, expiration)
+}
+
+// get 返回未过期的条目并标记为最近使用，调用方需持有锁
+func (mc *MemoryCache) get(key string) *memoryEntry {
+	elem, ok := mc.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		mc.remove(elem)
+		return nil
+	}
+	mc.lru.MoveToFront(elem)
+	return entry
+}
+
+// set 写入条目，超出容量时淘汰最久未使用的键，调用方需持有锁
+func (mc *MemoryCache) set(key string, data []byte, expiration time.Duration) {
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = time.Now().Add(expiration)
+	}
+
+	if elem, ok := mc.entries[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = data
+		entry.expiresAt = expiresAt
+		mc.lru.MoveToFront(elem)
+		return
+	}
+
+	mc.entries[key] = mc.lru.PushFront(&memoryEntry{key: key, value: data, expiresAt: expiresAt})
+	if mc.maxEntries == 0 {
+		if mc.lru.Len() >= mc.sweepAt {
+			mc.removeExpired()
+			mc.sweepAt = max(2*mc.lru.Len(), DefaultMemoryCacheEntries)
+		}
+		return
+	}
+	for mc.lru.Len() > mc.maxEntries {
+		mc.remove(mc.lru.Back())
+	}
+}
+
+// removeExpired 删除所有已过期的条目，调用方需持有锁
+func (mc *MemoryCache) removeExpired() {
+	now := time.Now()
+	for elem := mc.lru.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*memoryEntry)
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			mc.remove(elem)
+		}
+		elem = next
+	}
+}
+
+func (mc *MemoryCache) remove(elem *list.Element) {
+	mc.lru.Remove(elem)
+	delete(mc.entries, elem.Value.(*memoryEntry).key)
+}
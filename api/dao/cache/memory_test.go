@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryCache(2)
+	_ = c.Set("a", 1, 0)
+	_ = c.Set("b", 2, 0)
+	var v int
+	_ = c.Get("a", &v)
+	_ = c.Set("c", 3, 0)
+
+	if err := c.Get("b", &v); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Get(b) err = %v, want ErrCacheMiss", err)
+	}
+	if !c.Exists("a") || !c.Exists("c") {
+		t.Fatal("recently used keys were evicted")
+	}
+}
+
+// 安全状态存储不按容量淘汰，大量写入后最早的吊销记录仍然存在
+func TestStateMemoryCacheNeverEvicts(t *testing.T) {
+	c := NewStateMemoryCache()
+	if err := c.Set("revoked:jti:first", true, time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	for i := 0; i < 2*DefaultMemoryCacheEntries; i++ {
+		_ = c.Set("login:ip:"+strconv.Itoa(i), i, time.Hour)
+	}
+	if !c.Exists("revoked:jti:first") {
+		t.Fatal("revocation entry evicted from the state cache")
+	}
+}
+
+func TestStateMemoryCacheSweepsExpired(t *testing.T) {
+	c := NewStateMemoryCache()
+	for i := 0; i < DefaultMemoryCacheEntries-1; i++ {
+		_ = c.Set("short:"+strconv.Itoa(i), i, time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	_ = c.Set("long", 1, time.Hour)
+
+	mc := c.(*MemoryCache)
+	mc.mu.Lock()
+	n := len(mc.entries)
+	mc.mu.Unlock()
+	if n != 1 {
+		t.Fatalf("entries after sweep = %d, want 1", n)
+	}
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type cacheOIDCStateRepo struct {
+	cache Cache
+}
+
+func NewCacheOIDCStateRepo(cache Cache) dao.OIDCStateRepository {
+	return &cacheOIDCStateRepo{
+		cache: cache,
+	}
+}
+
+func (repo *cacheOIDCStateRepo) SaveState(state string, value *model.OIDCState, ttl time.Duration) error {
+	if repo.cache == nil {
+		return errors.New("cache unavailable")
+	}
+	return repo.cache.Set(fmt.Sprintf("oidc:state:%s", state), value, ttl)
+}
+
+func (repo *cacheOIDCStateRepo) ConsumeState(state string) (*model.OIDCState, error) {
+	if repo.cache == nil {
+		return nil, errors.New("cache unavailable")
+	}
+
+	// 原子计数保证并发回调时只有一个请求能取出
+	used, err := repo.cache.Incr(fmt.Sprintf("oidc:state:used:%s", state), 10*time.Minute)
+	if err != nil {
+		return nil, errors.New("cache unavailable")
+	}
+	if used > 1 {
+		return nil, errors.New("state invalid or expired")
+	}
+
+	key := fmt.Sprintf("oidc:state:%s", state)
+	var value model.OIDCState
+	if err := repo.cache.Get(key, &value); err != nil {
+		return nil, errors.New("state invalid or expired")
+	}
+	_ = repo.cache.Clean(key)
+	return &value, nil
+}
@@ -3,7 +3,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"math/rand"
 	"sync"
 	"time"
@@ -41,29 +41,111 @@ func (rc *RedisClient) Set(key string, value interface{}, expiration time.Durati
 
 func (rc *RedisClient) Get(key string, dest interface{}) error {
 	data, err := rc.client.Get(rc.ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrCacheMiss
+	}
 	if err != nil {
 		return err
 	}
 	return json.Unmarshal([]byte(data), dest)
 }
 
+// getWithTTL 读取原始 JSON 及剩余过期时间，不过期的键返回 0
+func (rc *RedisClient) getWithTTL(key string) ([]byte, time.Duration, error) {
+	pipe := rc.client.Pipeline()
+	getCmd := pipe.Get(rc.ctx, key)
+	ttlCmd := pipe.PTTL(rc.ctx, key)
+	_, err := pipe.Exec(rc.ctx)
+	if errors.Is(err, redis.Nil) {
+		return nil, 0, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ttl := ttlCmd.Val()
+	if ttl < 0 {
+		ttl = 0
+	}
+	return []byte(getCmd.Val()), ttl, nil
+}
+
+// incrScript 自增与设置过期放在同一脚本中，避免计数键永不过期
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+func (rc *RedisClient) Incr(key string, expiration time.Duration) (int64, error) {
+	return incrScript.Run(rc.ctx, rc.client, []string{key}, expiration.Milliseconds()).Int64()
+}
+
 // RandExp 防止缓存雪崩
 func (rc *RedisClient) RandExp(base time.Duration) time.Duration {
+	return randExp(base)
+}
+
+// randExp 在 base 上下浮动 10%
+func randExp(base time.Duration) time.Duration {
+	if base < 5 {
+		return base
+	}
 	jitter := rand.Int63n(int64(base/5)) - int64(base/10)
 	return time.Duration(int64(base) + jitter)
 }
 
-// Lock 获取分布式锁
-func (rc *RedisClient) Lock(key string, expire time.Duration) (bool, error) {
-	lockKey := fmt.Sprintf("lock:%s", key)
-	suc, err := rc.client.SetNX(rc.ctx, lockKey, "1", expire).Result()
-	return suc, err
+// fenceKey 全局递增的 fencing token，所有锁共用，对每把锁同样单调递增
+const fenceKey = "lock:fence"
+
+// obtainScript 获取锁与生成 fencing token 放在同一脚本中
+var obtainScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// releaseScript 比较持有者后删除，避免误删他人的锁
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendScript 比较持有者后续期
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// TryLock 获取分布式锁，已被占用时返回 ErrLockNotAcquired
+func (rc *RedisClient) TryLock(key string, ttl time.Duration) (*Lock, error) {
+	return tryLock(rc, key, ttl)
+}
+
+// AcquireLock 阻塞获取分布式锁，直到成功或 ctx 结束
+func (rc *RedisClient) AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	return acquireLock(ctx, rc, key, ttl)
+}
+
+func (rc *RedisClient) obtainLock(key, owner string, ttl time.Duration) (int64, error) {
+	return obtainScript.Run(rc.ctx, rc.client, []string{key, fenceKey}, owner, ttl.Milliseconds()).Int64()
+}
+
+func (rc *RedisClient) releaseLock(key, owner string) (bool, error) {
+	n, err := releaseScript.Run(rc.ctx, rc.client, []string{key}, owner).Int64()
+	return n == 1, err
 }
 
-// Unlock 释放分布式锁
-func (rc *RedisClient) Unlock(key string) error {
-	lockKey := fmt.Sprintf("lock:%s", key)
-	return rc.client.Del(rc.ctx, lockKey).Err()
+func (rc *RedisClient) extendLock(key, owner string, ttl time.Duration) (bool, error) {
+	n, err := extendScript.Run(rc.ctx, rc.client, []string{key}, owner, ttl.Milliseconds()).Int64()
+	return n == 1, err
 }
 
 // Clean 删除缓存
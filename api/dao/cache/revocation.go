@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"GoGin/api/dao"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+type cacheRevocationRepo struct {
+	cache    Cache
+	maxTTL   time.Duration // 访问令牌的最长有效期，水位线只需保存这么久
+	failOpen bool          // 缓存不可用时放行(true)或拒绝(false)
+}
+
+func NewCacheRevocationRepo(cache Cache, maxTTL time.Duration, failOpen bool) dao.RevocationRepository {
+	return &cacheRevocationRepo{
+		cache:    cache,
+		maxTTL:   maxTTL,
+		failOpen: failOpen,
+	}
+}
+
+func (repo *cacheRevocationRepo) RevokeToken(jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if repo.cache == nil {
+		return dao.ErrRevocationUnavailable
+	}
+
+	key := fmt.Sprintf("revoked:jti:%s", jti)
+	if err := repo.cache.Set(key, true, ttl); err != nil {
+		return dao.ErrRevocationUnavailable
+	}
+	return nil
+}
+
+func (repo *cacheRevocationRepo) IsTokenRevoked(jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+
+	var revoked bool
+	key := fmt.Sprintf("revoked:jti:%s", jti)
+	err := repo.get(key, &revoked)
+	if err != nil {
+		return repo.degrade(err)
+	}
+	return revoked, nil
+}
+
+func (repo *cacheRevocationRepo) RevokeUserTokensBefore(userID int, t time.Time) error {
+	if repo.cache == nil {
+		return dao.ErrRevocationUnavailable
+	}
+
+	key := fmt.Sprintf("revoked:user:%d", userID)
+	if err := repo.cache.Set(key, t.Unix(), repo.maxTTL); err != nil {
+		return dao.ErrRevocationUnavailable
+	}
+	return nil
+}
+
+func (repo *cacheRevocationRepo) IsUserTokenRevoked(userID int, issuedAt time.Time) (bool, error) {
+	var watermark int64
+	key := fmt.Sprintf("revoked:user:%d", userID)
+	err := repo.get(key, &watermark)
+	if err != nil {
+		return repo.degrade(err)
+	}
+	// iat 只精确到秒，与水位线同一秒签发的令牌无法区分先后，一律视为已吊销
+	return issuedAt.Unix() <= watermark, nil
+}
+
+func (repo *cacheRevocationRepo) RevokeSessionTokens(sessionID string) error {
+	if repo.cache == nil {
+		return dao.ErrRevocationUnavailable
+	}
+
+	key := fmt.Sprintf("revoked:sid:%s", sessionID)
+	if err := repo.cache.Set(key, true, repo.maxTTL); err != nil {
+		return dao.ErrRevocationUnavailable
+	}
+	return nil
+}
+
+func (repo *cacheRevocationRepo) IsSessionRevoked(sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	var revoked bool
+	key := fmt.Sprintf("revoked:sid:%s", sessionID)
+	err := repo.get(key, &revoked)
+	if err != nil {
+		return repo.degrade(err)
+	}
+	return revoked, nil
+}
+
+func (repo *cacheRevocationRepo) get(key string, dest interface{}) error {
+	if repo.cache == nil {
+		return dao.ErrRevocationUnavailable
+	}
+	return repo.cache.Get(key, dest)
+}
+
+// degrade 未命中表示未吊销；缓存故障时按 failOpen 策略处理
+func (repo *cacheRevocationRepo) degrade(err error) (bool, error) {
+	if errors.Is(err, ErrCacheMiss) {
+		return false, nil
+	}
+	if repo.failOpen {
+		log.Println("revocation store unavailable, fail open:", err)
+		return false, nil
+	}
+	return true, dao.ErrRevocationUnavailable
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// iat 只精确到秒：水位线所在秒及之前签发的令牌视为已吊销，之后的秒签发的令牌有效
+func TestUserTokenWatermark(t *testing.T) {
+	repo := NewCacheRevocationRepo(NewStateMemoryCache(), time.Hour, false)
+	revokedAt := time.Unix(1700000000, 500*int64(time.Millisecond))
+	if err := repo.RevokeUserTokensBefore(1, revokedAt); err != nil {
+		t.Fatalf("RevokeUserTokensBefore: %v", err)
+	}
+
+	tests := []struct {
+		issuedAt time.Time
+		want     bool
+	}{
+		{time.Unix(1699999999, 0), true},
+		{time.Unix(1700000000, 0), true},
+		{time.Unix(1700000000, 900*int64(time.Millisecond)), true},
+		{time.Unix(1700000001, 0), false},
+	}
+	for _, tt := range tests {
+		revoked, err := repo.IsUserTokenRevoked(1, tt.issuedAt)
+		if err != nil || revoked != tt.want {
+			t.Errorf("IsUserTokenRevoked(%v) = %v, %v, want %v", tt.issuedAt, revoked, err, tt.want)
+		}
+	}
+	if revoked, err := repo.IsUserTokenRevoked(2, time.Unix(1699999999, 0)); err != nil || revoked {
+		t.Errorf("other user revoked = %v, %v, want false", revoked, err)
+	}
+}
@@ -9,4 +9,6 @@ type CourseRepository interface {
 	CheckInfo() ([]model.Course, error)
 	AddCourse(Course model.Course) error
 	CheckCourse(courseID int) (model.Course, error)
+	// DropAllEnrollments 退掉学生的全部课程并同步选课人数
+	DropAllEnrollments(studentID int) error
 }
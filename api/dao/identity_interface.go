@@ -0,0 +1,21 @@
+package dao
+
+import (
+	"GoGin/internal/model"
+	"time"
+)
+
+type IdentityRepository interface {
+	CreateIdentity(identity *model.UserIdentity) error
+	GetIdentity(provider, subject string) (*model.UserIdentity, error)
+	ListUserIdentities(userID int) ([]model.UserIdentity, error)
+	DeleteIdentity(userID, identityID int) error
+	// DeleteUserIdentities 彻底删除账号时解绑该用户的全部外部身份
+	DeleteUserIdentities(userID int) error
+}
+
+// OIDCStateRepository 授权请求的临时状态，每个 state 只能取出一次
+type OIDCStateRepository interface {
+	SaveState(state string, value *model.OIDCState, ttl time.Duration) error
+	ConsumeState(state string) (*model.OIDCState, error)
+}
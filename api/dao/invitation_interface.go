@@ -0,0 +1,21 @@
+package dao
+
+import "GoGin/internal/model"
+
+type InvitationRepository interface {
+	CreateInvitation(invitation *model.Invitation) error
+	ListInvitations() ([]model.Invitation, error)
+	RevokeInvitation(invitationID int) error
+	// UseInvitation 原子地占用一次邀请码，过期、吊销或次数用尽时返回错误
+	UseInvitation(codeHash string) (*model.Invitation, error)
+	// ReleaseInvitation 注册失败时归还占用的次数
+	ReleaseInvitation(invitationID int) error
+
+	CreateRoleRequest(request *model.RoleRequest) error
+	GetRoleRequest(requestID int) (*model.RoleRequest, error)
+	ListRoleRequests(status string) ([]model.RoleRequest, error)
+	ListUserRoleRequests(userID int) ([]model.RoleRequest, error)
+	HasPendingRoleRequest(userID int) (bool, error)
+	// DecideRoleRequest 仅处理 pending 状态的申请
+	DecideRoleRequest(requestID int, status string, decidedBy int, note string) error
+}
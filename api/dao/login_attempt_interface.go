@@ -0,0 +1,15 @@
+package dao
+
+import "time"
+
+// LoginAttemptRepository 登录失败计数与临时封禁，key 为 "account:<id>" 或 "ip:<addr>" 形式
+type LoginAttemptRepository interface {
+	// RecordFailure 失败次数加一并返回当前次数，计数在 window 后清零
+	RecordFailure(key string, window time.Duration) (int64, error)
+	// Block 在 d 时间内拒绝该 key 的登录
+	Block(key string, d time.Duration) error
+	// BlockedFor 剩余封禁时间，未封禁时返回 0
+	BlockedFor(key string) (time.Duration, error)
+	// Reset 清除失败计数和封禁
+	Reset(key string) error
+}
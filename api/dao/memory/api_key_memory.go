@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryAPIKeyRepo struct {
+	mu     sync.RWMutex
+	keys   map[int]*model.APIKey
+	byHash map[string]int
+	nextID int
+}
+
+func NewMemoryAPIKeyRepo() dao.APIKeyRepository {
+	return &memoryAPIKeyRepo{
+		keys:   make(map[int]*model.APIKey),
+		byHash: make(map[string]int),
+		nextID: 1,
+	}
+}
+
+func (repo *memoryAPIKeyRepo) CreateAPIKey(key *model.APIKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.byHash[key.KeyHash]; ok {
+		return errors.New("api key create failed")
+	}
+	key.ID = repo.nextID
+	repo.nextID++
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	repo.keys[key.ID] = copyAPIKey(key)
+	repo.byHash[key.KeyHash] = key.ID
+	return nil
+}
+
+func (repo *memoryAPIKeyRepo) GetAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	id, ok := repo.byHash[keyHash]
+	if !ok {
+		return nil, errors.New("api key not found")
+	}
+	return copyAPIKey(repo.keys[id]), nil
+}
+
+func (repo *memoryAPIKeyRepo) ListUserAPIKeys(userID int) ([]model.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	keys := make([]model.APIKey, 0)
+	for _, key := range repo.keys {
+		if key.UserID == userID {
+			keys = append(keys, *copyAPIKey(key))
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID > keys[j].ID })
+	return keys, nil
+}
+
+func (repo *memoryAPIKeyRepo) RevokeAPIKey(userID, keyID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, ok := repo.keys[keyID]
+	if !ok || key.UserID != userID {
+		return errors.New("api key not found")
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = now()
+	}
+	return nil
+}
+
+func (repo *memoryAPIKeyRepo) TouchAPIKey(keyID int, usedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if key, ok := repo.keys[keyID]; ok {
+		key.LastUsedAt = timePtr(&usedAt)
+	}
+	return nil
+}
+
+func (repo *memoryAPIKeyRepo) DeleteUserAPIKeys(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, key := range repo.keys {
+		if key.UserID == userID {
+			delete(repo.byHash, key.KeyHash)
+			delete(repo.keys, id)
+		}
+	}
+	return nil
+}
+
+func copyAPIKey(key *model.APIKey) *model.APIKey {
+	c := *key
+	c.Scopes = append([]string(nil), key.Scopes...)
+	c.LastUsedAt = timePtr(key.LastUsedAt)
+	c.RevokedAt = timePtr(key.RevokedAt)
+	return &c
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"sync"
+	"time"
+)
+
+// memoryAuditRepo 只追加，切片按 audit_id 升序
+type memoryAuditRepo struct {
+	mu      sync.RWMutex
+	entries []model.AuditEntry
+	nextID  int
+}
+
+func NewMemoryAuditRepo() dao.AuditRepository {
+	return &memoryAuditRepo{
+		nextID: 1,
+	}
+}
+
+func (repo *memoryAuditRepo) AppendAudit(entry *model.AuditEntry) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	entry.ID = repo.nextID
+	repo.nextID++
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	repo.entries = append(repo.entries, *entry)
+	return nil
+}
+
+func (repo *memoryAuditRepo) ListAudit(filter model.AuditFilter) ([]model.AuditEntry, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	entries := make([]model.AuditEntry, 0)
+	for i := len(repo.entries) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(entries) >= filter.Limit {
+			break
+		}
+		entry := repo.entries[i]
+		if filter.Cursor > 0 && entry.ID >= filter.Cursor {
+			continue
+		}
+		if filter.ActorID != 0 && entry.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.Action != "" && entry.Action != filter.Action {
+			continue
+		}
+		if !filter.From.IsZero() && entry.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !entry.CreatedAt.Before(filter.To) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// memoryCourseRepo 选课人数检查与写入在同一把锁内完成，不会超过课程容量
+type memoryCourseRepo struct {
+	mu      sync.RWMutex
+	courses map[int]*model.Course
+	nextID  int
+	// 学生ID -> 已选课程ID
+	enrollments map[int]map[int]bool
+}
+
+func NewMemoryCourseRepo() dao.CourseRepository {
+	return &memoryCourseRepo{
+		courses:     make(map[int]*model.Course),
+		nextID:      1,
+		enrollments: make(map[int]map[int]bool),
+	}
+}
+
+// PickCourse 学生即登录用户，内存存储不单独维护学生表
+func (repo *memoryCourseRepo) PickCourse(StudentID, CourseID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	// 检查课程是否存在
+	course, ok := repo.courses[CourseID]
+	if !ok {
+		return errors.New("course Not Found")
+	}
+
+	// 检查课程是否已满
+	if course.Enroll >= course.Capital {
+		return errors.New("course is full")
+	}
+
+	// 是否重复选择
+	if repo.enrollments[StudentID][CourseID] {
+		return errors.New("enrollment exists")
+	}
+
+	// 创建选课关系并更新人数
+	if repo.enrollments[StudentID] == nil {
+		repo.enrollments[StudentID] = make(map[int]bool)
+	}
+	repo.enrollments[StudentID][CourseID] = true
+	course.Enroll++
+	return nil
+}
+
+func (repo *memoryCourseRepo) DropCourse(StudentID, CourseID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	//是否存在记录
+	if !repo.enrollments[StudentID][CourseID] {
+		return errors.New("enrollment Not Found")
+	}
+
+	//删除并更新人数
+	delete(repo.enrollments[StudentID], CourseID)
+	if course, ok := repo.courses[CourseID]; ok && course.Enroll > 0 {
+		course.Enroll--
+	}
+	return nil
+}
+
+func (repo *memoryCourseRepo) CheckEnrollment(studentID int) ([]model.Enrollment, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	enrollments := make([]model.Enrollment, 0, len(repo.enrollments[studentID]))
+	for courseID := range repo.enrollments[studentID] {
+		enrollment := model.Enrollment{
+			StudentID: studentID,
+			CourseID:  courseID,
+		}
+		if course, ok := repo.courses[courseID]; ok {
+			enrollment.Course = *course
+		}
+		enrollments = append(enrollments, enrollment)
+	}
+	sort.Slice(enrollments, func(i, j int) bool { return enrollments[i].CourseID < enrollments[j].CourseID })
+	return enrollments, nil
+}
+
+func (repo *memoryCourseRepo) CheckInfo() ([]model.Course, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	courses := make([]model.Course, 0, len(repo.courses))
+	for _, course := range repo.courses {
+		courses = append(courses, *course)
+	}
+	sort.Slice(courses, func(i, j int) bool { return courses[i].ID < courses[j].ID })
+	return courses, nil
+}
+
+func (repo *memoryCourseRepo) AddCourse(Course model.Course) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	Course.ID = repo.nextID
+	repo.nextID++
+	Course.Enrollments = nil
+	repo.courses[Course.ID] = &Course
+	return nil
+}
+
+func (repo *memoryCourseRepo) CheckCourse(courseID int) (model.Course, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	course, ok := repo.courses[courseID]
+	if !ok {
+		return model.Course{}, errors.New("course not found")
+	}
+	return *course, nil
+}
+
+func (repo *memoryCourseRepo) DropAllEnrollments(studentID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for courseID := range repo.enrollments[studentID] {
+		if course, ok := repo.courses[courseID]; ok && course.Enroll > 0 {
+			course.Enroll--
+		}
+	}
+	delete(repo.enrollments, studentID)
+	return nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"GoGin/internal/model"
+	"sync"
+	"testing"
+)
+
+func TestPickCourseConcurrentCapacity(t *testing.T) {
+	repo := NewMemoryCourseRepo()
+	if err := repo.AddCourse(model.Course{Name: "math", Capital: 10}); err != nil {
+		t.Fatalf("AddCourse: %v", err)
+	}
+
+	const students = 50
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+	)
+	for i := 1; i <= students; i++ {
+		wg.Add(1)
+		go func(studentID int) {
+			defer wg.Done()
+			if err := repo.PickCourse(studentID, 1); err == nil {
+				mu.Lock()
+				success++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if success != 10 {
+		t.Fatalf("successful picks = %d, want 10", success)
+	}
+	course, err := repo.CheckCourse(1)
+	if err != nil {
+		t.Fatalf("CheckCourse: %v", err)
+	}
+	if course.Enroll != 10 {
+		t.Fatalf("Enroll = %d, want 10", course.Enroll)
+	}
+	enrolled := 0
+	for i := 1; i <= students; i++ {
+		enrollments, err := repo.CheckEnrollment(i)
+		if err != nil {
+			t.Fatalf("CheckEnrollment: %v", err)
+		}
+		enrolled += len(enrollments)
+	}
+	if enrolled != 10 {
+		t.Fatalf("enrollments = %d, want 10", enrolled)
+	}
+	if err := repo.PickCourse(students+1, 1); err == nil || err.Error() != "course is full" {
+		t.Fatalf("PickCourse on full course: err = %v", err)
+	}
+}
+
+func TestPickCourseDuplicate(t *testing.T) {
+	repo := NewMemoryCourseRepo()
+	if err := repo.AddCourse(model.Course{Name: "math", Capital: 10}); err != nil {
+		t.Fatalf("AddCourse: %v", err)
+	}
+
+	if err := repo.PickCourse(1, 1); err != nil {
+		t.Fatalf("PickCourse: %v", err)
+	}
+	if err := repo.PickCourse(1, 1); err == nil || err.Error() != "enrollment exists" {
+		t.Fatalf("duplicate PickCourse: err = %v", err)
+	}
+	course, _ := repo.CheckCourse(1)
+	if course.Enroll != 1 {
+		t.Fatalf("Enroll = %d, want 1", course.Enroll)
+	}
+
+	// 退课后可以重新选择
+	if err := repo.DropCourse(1, 1); err != nil {
+		t.Fatalf("DropCourse: %v", err)
+	}
+	if err := repo.PickCourse(1, 1); err != nil {
+		t.Fatalf("PickCourse after drop: %v", err)
+	}
+}
@@ -1,84 +1,21 @@
 package memory
 
-// ================================未适配mysql前采用的内存存储数据==============================
+import "time"
 
+// ================================内存存储：不依赖 MySQL，用于演示和测试，进程重启后数据丢失==============================
 //
-//import (
-//	"GoGin/internal/model"
-//	"GoGin/internal/dao"
-//	"errors"
-//	"sync"
-//)
-//
-//type memoryUserRepository struct {
-//	database    map[string]*model.User // 以username存储用户信息
-//	emailToData map[string]string      // 存储email对应username
-//	DataSync    sync.Mutex             // 并发安全
-//}
-//
-//func NewMemoryUserRepository() dao.UserRepository {
-//	return &memoryUserRepository{
-//		database:    make(map[string]*model.User),
-//		emailToData: make(map[string]string),
-//	}
-//}
-//
-//// AddUser 添加新用户
-//func (repo *memoryUserRepository) AddUser(user *model.User) error {
-//	repo.DataSync.Lock()
-//	defer repo.DataSync.Unlock()
-//
-//	if _, ok := repo.database[user.Username]; ok {
-//		return errors.New("user already exists")
-//	}
-//
-//	if _, ok := repo.emailToData[user.Email]; ok {
-//		return errors.New("email already exists")
-//	}
-//
-//	repo.database[user.Username] = user
-//	repo.emailToData[user.Email] = user.Username
-//
-//	return nil
-//}
-//
-//// SelectByUsername 登录时：返回正确密码供检查
-//func (repo *memoryUserRepository) SelectByUsername(username string) (*model.User, error) {
-//	repo.DataSync.Lock()
-//	defer repo.DataSync.Unlock()
-//
-//	user, ok := repo.database[username]
-//	if !ok {
-//		return nil, errors.New("user not found")
-//	}
-//
-//	return user, nil
-//}
-//
-//// SelectByEmail 登录时：返回正确密码供检查
-//func (repo *memoryUserRepository) SelectByEmail(email string) (*model.User, error) {
-//	repo.DataSync.Lock()
-//	defer repo.DataSync.Unlock()
-//
-//	username, ok := repo.emailToData[email]
-//	if !ok {
-//		return nil, errors.New("email not found")
-//	}
-//
-//	user, ok := repo.database[username]
-//	if !ok {
-//		return nil, errors.New("user not found")
-//	}
-//
-//	return user, nil
-//}
-//
-//func (repo *memoryUserRepository) Exists(username, email string) bool {
-//	repo.DataSync.Lock()
-//	defer repo.DataSync.Unlock()
-//
-//	_, userExist := repo.database[username]
-//	_, emailExist := repo.emailToData[email]
-//
-//	return userExist || emailExist
-//}
+// 每个仓库用一把读写锁保护自己的数据，读写都返回副本，调用方修改返回值不会影响存储
+
+// timePtr 复制时间指针，避免与存储共享
+func timePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
+
+func now() *time.Time {
+	t := time.Now()
+	return &t
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryIdentityRepo struct {
+	mu         sync.RWMutex
+	identities map[int]*model.UserIdentity
+	nextID     int
+}
+
+func NewMemoryIdentityRepo() dao.IdentityRepository {
+	return &memoryIdentityRepo{
+		identities: make(map[int]*model.UserIdentity),
+		nextID:     1,
+	}
+}
+
+func (repo *memoryIdentityRepo) CreateIdentity(identity *model.UserIdentity) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	// provider + subject 唯一
+	for _, existing := range repo.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return errors.New("identity already linked")
+		}
+	}
+	identity.ID = repo.nextID
+	repo.nextID++
+	if identity.CreatedAt.IsZero() {
+		identity.CreatedAt = time.Now()
+	}
+	c := *identity
+	repo.identities[identity.ID] = &c
+	return nil
+}
+
+func (repo *memoryIdentityRepo) GetIdentity(provider, subject string) (*model.UserIdentity, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, identity := range repo.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			c := *identity
+			return &c, nil
+		}
+	}
+	return nil, errors.New("identity not found")
+}
+
+func (repo *memoryIdentityRepo) ListUserIdentities(userID int) ([]model.UserIdentity, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	identities := make([]model.UserIdentity, 0)
+	for _, identity := range repo.identities {
+		if identity.UserID == userID {
+			identities = append(identities, *identity)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool { return identities[i].ID < identities[j].ID })
+	return identities, nil
+}
+
+func (repo *memoryIdentityRepo) DeleteIdentity(userID, identityID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	identity, ok := repo.identities[identityID]
+	if !ok || identity.UserID != userID {
+		return errors.New("identity not found")
+	}
+	delete(repo.identities, identityID)
+	return nil
+}
+
+func (repo *memoryIdentityRepo) DeleteUserIdentities(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, identity := range repo.identities {
+		if identity.UserID == userID {
+			delete(repo.identities, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryInvitationRepo struct {
+	mu            sync.RWMutex
+	invitations   map[int]*model.Invitation
+	nextID        int
+	requests      map[int]*model.RoleRequest
+	nextRequestID int
+}
+
+func NewMemoryInvitationRepo() dao.InvitationRepository {
+	return &memoryInvitationRepo{
+		invitations:   make(map[int]*model.Invitation),
+		nextID:        1,
+		requests:      make(map[int]*model.RoleRequest),
+		nextRequestID: 1,
+	}
+}
+
+func (repo *memoryInvitationRepo) CreateInvitation(invitation *model.Invitation) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, existing := range repo.invitations {
+		if existing.CodeHash == invitation.CodeHash {
+			return errors.New("invitation create failed")
+		}
+	}
+	invitation.ID = repo.nextID
+	repo.nextID++
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+	repo.invitations[invitation.ID] = copyInvitation(invitation)
+	return nil
+}
+
+func (repo *memoryInvitationRepo) ListInvitations() ([]model.Invitation, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	invitations := make([]model.Invitation, 0, len(repo.invitations))
+	for _, invitation := range repo.invitations {
+		invitations = append(invitations, *copyInvitation(invitation))
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].ID > invitations[j].ID })
+	return invitations, nil
+}
+
+func (repo *memoryInvitationRepo) RevokeInvitation(invitationID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	invitation, ok := repo.invitations[invitationID]
+	if !ok || invitation.RevokedAt != nil {
+		return errors.New("invitation not found")
+	}
+	invitation.RevokedAt = now()
+	return nil
+}
+
+func (repo *memoryInvitationRepo) UseInvitation(codeHash string) (*model.Invitation, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	// 检查与计数在同一把锁内完成，并发注册不会超过次数上限
+	for _, invitation := range repo.invitations {
+		if invitation.CodeHash != codeHash {
+			continue
+		}
+		if invitation.RevokedAt != nil || !invitation.ExpiresAt.After(time.Now()) || invitation.Uses >= invitation.MaxUses {
+			break
+		}
+		invitation.Uses++
+		return copyInvitation(invitation), nil
+	}
+	return nil, errors.New("invitation code invalid or expired")
+}
+
+func (repo *memoryInvitationRepo) ReleaseInvitation(invitationID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if invitation, ok := repo.invitations[invitationID]; ok && invitation.Uses > 0 {
+		invitation.Uses--
+	}
+	return nil
+}
+
+func (repo *memoryInvitationRepo) CreateRoleRequest(request *model.RoleRequest) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	request.ID = repo.nextRequestID
+	repo.nextRequestID++
+	if request.CreatedAt.IsZero() {
+		request.CreatedAt = time.Now()
+	}
+	repo.requests[request.ID] = copyRoleRequest(request)
+	return nil
+}
+
+func (repo *memoryInvitationRepo) GetRoleRequest(requestID int) (*model.RoleRequest, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	request, ok := repo.requests[requestID]
+	if !ok {
+		return nil, errors.New("role request not found")
+	}
+	return copyRoleRequest(request), nil
+}
+
+func (repo *memoryInvitationRepo) ListRoleRequests(status string) ([]model.RoleRequest, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	requests := make([]model.RoleRequest, 0)
+	for _, request := range repo.requests {
+		if status == "" || request.Status == status {
+			requests = append(requests, *copyRoleRequest(request))
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].ID < requests[j].ID })
+	return requests, nil
+}
+
+func (repo *memoryInvitationRepo) ListUserRoleRequests(userID int) ([]model.RoleRequest, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	requests := make([]model.RoleRequest, 0)
+	for _, request := range repo.requests {
+		if request.UserID == userID {
+			requests = append(requests, *copyRoleRequest(request))
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool { return requests[i].ID > requests[j].ID })
+	return requests, nil
+}
+
+func (repo *memoryInvitationRepo) HasPendingRoleRequest(userID int) (bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, request := range repo.requests {
+		if request.UserID == userID && request.Status == model.RoleRequestPending {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repo *memoryInvitationRepo) DecideRoleRequest(requestID int, status string, decidedBy int, note string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	request, ok := repo.requests[requestID]
+	if !ok || request.Status != model.RoleRequestPending {
+		return errors.New("role request already decided")
+	}
+	request.Status = status
+	request.DecidedBy = &decidedBy
+	request.Note = note
+	request.DecidedAt = now()
+	return nil
+}
+
+func copyInvitation(invitation *model.Invitation) *model.Invitation {
+	c := *invitation
+	c.RevokedAt = timePtr(invitation.RevokedAt)
+	return &c
+}
+
+func copyRoleRequest(request *model.RoleRequest) *model.RoleRequest {
+	c := *request
+	if request.DecidedBy != nil {
+		decidedBy := *request.DecidedBy
+		c.DecidedBy = &decidedBy
+	}
+	c.DecidedAt = timePtr(request.DecidedAt)
+	return &c
+}
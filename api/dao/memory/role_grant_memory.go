@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"sync"
+	"time"
+)
+
+type memoryRoleGrantRepo struct {
+	mu     sync.RWMutex
+	grants []model.RoleGrant
+	nextID int
+}
+
+func NewMemoryRoleGrantRepo() dao.RoleGrantRepository {
+	return &memoryRoleGrantRepo{
+		nextID: 1,
+	}
+}
+
+func (repo *memoryRoleGrantRepo) RecordGrant(grant *model.RoleGrant) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	grant.ID = repo.nextID
+	repo.nextID++
+	if grant.CreatedAt.IsZero() {
+		grant.CreatedAt = time.Now()
+	}
+	repo.grants = append(repo.grants, *grant)
+	return nil
+}
+
+func (repo *memoryRoleGrantRepo) ListGrants(userID int) ([]model.RoleGrant, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	// 按追加顺序倒序返回，与 grant_id DESC 一致
+	grants := make([]model.RoleGrant, 0)
+	for i := len(repo.grants) - 1; i >= 0; i-- {
+		if userID == 0 || repo.grants[i].UserID == userID {
+			grants = append(grants, repo.grants[i])
+		}
+	}
+	return grants, nil
+}
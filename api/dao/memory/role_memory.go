@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sort"
+	"sync"
+)
+
+type memoryRoleRepo struct {
+	mu          sync.RWMutex
+	roles       map[string]*model.Role
+	permissions map[string]model.Permission
+	nextRoleID  int
+}
+
+// NewMemoryRoleRepo 与 MySQL 实现一样在创建时写入内置权限和角色
+func NewMemoryRoleRepo() dao.RoleRepository {
+	repo := &memoryRoleRepo{
+		roles:       make(map[string]*model.Role),
+		permissions: make(map[string]model.Permission),
+		nextRoleID:  1,
+	}
+	for i, permission := range model.DefaultPermissions {
+		permission.ID = i + 1
+		repo.permissions[permission.Name] = permission
+	}
+	names := make([]string, 0, len(model.DefaultRoles))
+	for name := range model.DefaultRoles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_ = repo.CreateRole(&model.Role{Name: name}, model.DefaultRoles[name])
+	}
+	return repo
+}
+
+func (repo *memoryRoleRepo) ListRoles() ([]model.Role, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	roles := make([]model.Role, 0, len(repo.roles))
+	for _, role := range repo.roles {
+		roles = append(roles, copyRole(role))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ID < roles[j].ID })
+	return roles, nil
+}
+
+func (repo *memoryRoleRepo) GetRole(name string) (*model.Role, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	role, ok := repo.roles[name]
+	if !ok {
+		return nil, errors.New("role not found")
+	}
+	r := copyRole(role)
+	return &r, nil
+}
+
+func (repo *memoryRoleRepo) CreateRole(role *model.Role, permissions []string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	perms, err := repo.findPermissions(permissions)
+	if err != nil {
+		return err
+	}
+	if _, ok := repo.roles[role.Name]; ok {
+		return errors.New("role create failed")
+	}
+	role.ID = repo.nextRoleID
+	repo.nextRoleID++
+	role.Permissions = perms
+	r := copyRole(role)
+	repo.roles[role.Name] = &r
+	return nil
+}
+
+func (repo *memoryRoleRepo) SetPermissions(roleName string, permissions []string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	role, ok := repo.roles[roleName]
+	if !ok {
+		return errors.New("role not found")
+	}
+	perms, err := repo.findPermissions(permissions)
+	if err != nil {
+		return err
+	}
+	role.Permissions = perms
+	return nil
+}
+
+func (repo *memoryRoleRepo) ListPermissions() ([]model.Permission, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	permissions := make([]model.Permission, 0, len(repo.permissions))
+	for _, permission := range repo.permissions {
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].ID < permissions[j].ID })
+	return permissions, nil
+}
+
+func (repo *memoryRoleRepo) GetPermissions(roleName string) ([]string, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	role, ok := repo.roles[roleName]
+	if !ok {
+		return nil, errors.New("role not found")
+	}
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		permissions = append(permissions, p.Name)
+	}
+	return permissions, nil
+}
+
+// findPermissions 按名称查找权限，存在未知权限时报错，调用方需持有锁
+func (repo *memoryRoleRepo) findPermissions(names []string) ([]model.Permission, error) {
+	seen := make(map[string]bool, len(names))
+	perms := make([]model.Permission, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		permission, ok := repo.permissions[name]
+		if !ok {
+			return nil, errors.New("unknown permission")
+		}
+		perms = append(perms, permission)
+	}
+	return perms, nil
+}
+
+func copyRole(role *model.Role) model.Role {
+	r := *role
+	r.Permissions = append([]model.Permission(nil), role.Permissions...)
+	return r
+}
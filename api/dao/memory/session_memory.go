@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memorySessionRepo struct {
+	mu       sync.RWMutex
+	sessions map[string]*model.Session
+}
+
+func NewMemorySessionRepo() dao.SessionRepository {
+	return &memorySessionRepo{
+		sessions: make(map[string]*model.Session),
+	}
+}
+
+func (repo *memorySessionRepo) CreateSession(session *model.Session) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.sessions[session.SessionID]; ok {
+		return errors.New("session create failed")
+	}
+	t := time.Now()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = t
+	}
+	session.UpdatedAt = t
+	s := *session
+	repo.sessions[session.SessionID] = &s
+	return nil
+}
+
+func (repo *memorySessionRepo) GetSession(sessionID string) (*model.Session, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	session, ok := repo.sessions[sessionID]
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	s := *session
+	return &s, nil
+}
+
+func (repo *memorySessionRepo) RotateSession(sessionID, oldHash, newHash string, expiresAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	// 比较和替换在同一把锁内完成，同一个旧令牌只能轮换一次
+	session, ok := repo.sessions[sessionID]
+	if !ok || session.Revoked || session.TokenHash != oldHash {
+		return errors.New("session already rotated")
+	}
+	session.TokenHash = newHash
+	session.ExpiresAt = expiresAt
+	session.UpdatedAt = time.Now()
+	return nil
+}
+
+func (repo *memorySessionRepo) RevokeSession(sessionID string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if session, ok := repo.sessions[sessionID]; ok {
+		session.Revoked = true
+		session.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (repo *memorySessionRepo) RevokeUserSessions(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	t := time.Now()
+	for _, session := range repo.sessions {
+		if session.UserID == userID && !session.Revoked {
+			session.Revoked = true
+			session.UpdatedAt = t
+		}
+	}
+	return nil
+}
+
+func (repo *memorySessionRepo) DeleteUserSessions(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, session := range repo.sessions {
+		if session.UserID == userID {
+			delete(repo.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (repo *memorySessionRepo) ListUserSessions(userID int) ([]model.Session, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	t := time.Now()
+	sessions := make([]model.Session, 0)
+	for _, session := range repo.sessions {
+		if session.UserID == userID && !session.Revoked && session.ExpiresAt.After(t) {
+			sessions = append(sessions, *session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (repo *memorySessionRepo) TouchSession(sessionID string, client model.ClientInfo, seenAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if session, ok := repo.sessions[sessionID]; ok {
+		session.LastSeenAt = seenAt
+		session.IP = client.IP
+		session.UserAgent = client.UserAgent
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sort"
+	"sync"
+)
+
+type memoryTodoRepo struct {
+	mu     sync.RWMutex
+	tasks  map[int]*model.TodoTask
+	nextID int
+}
+
+func NewMemoryTodoRepo() dao.TodoRepository {
+	return &memoryTodoRepo{
+		tasks:  make(map[int]*model.TodoTask),
+		nextID: 1,
+	}
+}
+
+func (repo *memoryTodoRepo) CreateTodoTask(task *model.TodoTask) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	task.ID = repo.nextID
+	repo.nextID++
+	repo.tasks[task.ID] = copyTask(task)
+	return nil
+}
+
+func (repo *memoryTodoRepo) DeleteTodoTask(taskID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.tasks[taskID]; !ok {
+		return errors.New("task not found")
+	}
+	delete(repo.tasks, taskID)
+	return nil
+}
+
+func (repo *memoryTodoRepo) FinishTodoTask(taskID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	task, ok := repo.tasks[taskID]
+	if !ok {
+		return errors.New("task not found")
+	}
+	task.Completed = true
+	return nil
+}
+
+func (repo *memoryTodoRepo) CheckTodoTask(userID int) ([]model.TodoTask, []model.TodoTask, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	todos := make([]model.TodoTask, 0)
+	dones := make([]model.TodoTask, 0)
+	for _, task := range repo.tasks {
+		if task.UserID != userID || task.ArchivedAt != nil {
+			continue
+		}
+		if task.Completed {
+			dones = append(dones, *copyTask(task))
+		} else {
+			todos = append(todos, *copyTask(task))
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ID < todos[j].ID })
+	sort.Slice(dones, func(i, j int) bool { return dones[i].ID < dones[j].ID })
+	return todos, dones, nil
+}
+
+func (repo *memoryTodoRepo) ArchiveUserTodos(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	archivedAt := now()
+	for _, task := range repo.tasks {
+		if task.UserID == userID && task.ArchivedAt == nil {
+			task.ArchivedAt = timePtr(archivedAt)
+		}
+	}
+	return nil
+}
+
+func (repo *memoryTodoRepo) UnarchiveUserTodos(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, task := range repo.tasks {
+		if task.UserID == userID {
+			task.ArchivedAt = nil
+		}
+	}
+	return nil
+}
+
+func (repo *memoryTodoRepo) DeleteUserTodos(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, task := range repo.tasks {
+		if task.UserID == userID {
+			delete(repo.tasks, id)
+		}
+	}
+	return nil
+}
+
+func copyTask(task *model.TodoTask) *model.TodoTask {
+	c := *task
+	c.ArchivedAt = timePtr(task.ArchivedAt)
+	return &c
+}
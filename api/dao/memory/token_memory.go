@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sync"
+	"time"
+)
+
+type memoryTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*model.OneTimeToken // 以令牌哈希为键
+	nextID int
+}
+
+func NewMemoryTokenRepo() dao.TokenRepository {
+	return &memoryTokenRepo{
+		tokens: make(map[string]*model.OneTimeToken),
+		nextID: 1,
+	}
+}
+
+func (repo *memoryTokenRepo) CreateToken(token *model.OneTimeToken) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.tokens[token.TokenHash]; ok {
+		return errors.New("token create failed")
+	}
+	token.ID = repo.nextID
+	repo.nextID++
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	repo.tokens[token.TokenHash] = copyToken(token)
+	return nil
+}
+
+func (repo *memoryTokenRepo) ConsumeToken(purpose, tokenHash string) (*model.OneTimeToken, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	// 检查与标记在同一把锁内完成，令牌只能被使用一次
+	token, ok := repo.tokens[tokenHash]
+	if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("token invalid or expired")
+	}
+	token.UsedAt = now()
+	return copyToken(token), nil
+}
+
+func (repo *memoryTokenRepo) DeleteUserTokens(userID int, purpose string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for hash, token := range repo.tokens {
+		if token.UserID == userID && token.Purpose == purpose {
+			delete(repo.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func copyToken(token *model.OneTimeToken) *model.OneTimeToken {
+	c := *token
+	c.UsedAt = timePtr(token.UsedAt)
+	return &c
+}
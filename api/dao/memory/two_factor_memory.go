@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sync"
+	"time"
+)
+
+type memoryTwoFactorRepo struct {
+	mu            sync.Mutex
+	twoFactors    map[int]*model.TwoFactor
+	recoveryCodes map[int][]*model.RecoveryCode
+	nextCodeID    int
+}
+
+func NewMemoryTwoFactorRepo() dao.TwoFactorRepository {
+	return &memoryTwoFactorRepo{
+		twoFactors:    make(map[int]*model.TwoFactor),
+		recoveryCodes: make(map[int][]*model.RecoveryCode),
+		nextCodeID:    1,
+	}
+}
+
+func (repo *memoryTwoFactorRepo) GetTwoFactor(userID int) (*model.TwoFactor, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	twoFactor, ok := repo.twoFactors[userID]
+	if !ok {
+		return nil, dao.ErrTwoFactorNotFound
+	}
+	return copyTwoFactor(twoFactor), nil
+}
+
+func (repo *memoryTwoFactorRepo) SaveTwoFactor(twoFactor *model.TwoFactor) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if existing, ok := repo.twoFactors[twoFactor.UserID]; ok && existing.Enabled {
+		return errors.New("two-factor save failed: two-factor already enabled")
+	}
+	t := time.Now()
+	if twoFactor.CreatedAt.IsZero() {
+		twoFactor.CreatedAt = t
+	}
+	twoFactor.UpdatedAt = t
+	repo.twoFactors[twoFactor.UserID] = copyTwoFactor(twoFactor)
+	return nil
+}
+
+func (repo *memoryTwoFactorRepo) EnableTwoFactor(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if twoFactor, ok := repo.twoFactors[userID]; ok {
+		twoFactor.Enabled = true
+		twoFactor.ConfirmedAt = now()
+		twoFactor.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (repo *memoryTwoFactorRepo) DisableTwoFactor(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.twoFactors, userID)
+	delete(repo.recoveryCodes, userID)
+	return nil
+}
+
+func (repo *memoryTwoFactorRepo) UseStep(userID int, step int64) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	twoFactor, ok := repo.twoFactors[userID]
+	if !ok || twoFactor.LastUsedStep >= step {
+		return false, nil
+	}
+	twoFactor.LastUsedStep = step
+	return true, nil
+}
+
+func (repo *memoryTwoFactorRepo) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	codes := make([]*model.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, &model.RecoveryCode{ID: repo.nextCodeID, UserID: userID, CodeHash: hash})
+		repo.nextCodeID++
+	}
+	repo.recoveryCodes[userID] = codes
+	return nil
+}
+
+func (repo *memoryTwoFactorRepo) UseRecoveryCode(userID int, codeHash string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, code := range repo.recoveryCodes[userID] {
+		if code.CodeHash == codeHash && code.UsedAt == nil {
+			code.UsedAt = now()
+			return nil
+		}
+	}
+	return errors.New("invalid recovery code")
+}
+
+func copyTwoFactor(twoFactor *model.TwoFactor) *model.TwoFactor {
+	c := *twoFactor
+	c.ConfirmedAt = timePtr(twoFactor.ConfirmedAt)
+	return &c
+}
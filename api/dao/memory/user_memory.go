@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type memoryUserRepo struct {
+	mu     sync.RWMutex
+	users  map[int]*model.User
+	nextID int
+	// 用户名、邮箱到 user_id 的索引，包含已注销的账号
+	byUsername map[string]int
+	byEmail    map[string]int
+}
+
+func NewMemoryUserRepo() dao.UserRepository {
+	return &memoryUserRepo{
+		users:      make(map[int]*model.User),
+		nextID:     1,
+		byUsername: make(map[string]int),
+		byEmail:    make(map[string]int),
+	}
+}
+
+func (repo *memoryUserRepo) AddUser(user *model.User) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.byUsername[user.Username]; ok {
+		return errors.New("user already exists")
+	}
+	if _, ok := repo.byEmail[user.Email]; ok {
+		return errors.New("email already exists")
+	}
+
+	user.UserID = repo.nextID
+	repo.nextID++
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	stored := copyUser(user)
+	repo.users[user.UserID] = stored
+	repo.byUsername[user.Username] = user.UserID
+	repo.byEmail[user.Email] = user.UserID
+	return nil
+}
+
+func (repo *memoryUserRepo) SelectByID(userID int) (*model.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, ok := repo.active(userID)
+	if !ok {
+		return nil, errors.New("user select failed")
+	}
+	return copyUser(user), nil
+}
+
+func (repo *memoryUserRepo) SelectByUsername(username string) (*model.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, ok := repo.active(repo.byUsername[username])
+	if !ok {
+		return nil, errors.New("username select failed")
+	}
+	return copyUser(user), nil
+}
+
+func (repo *memoryUserRepo) SelectByEmail(email string) (*model.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, ok := repo.active(repo.byEmail[email])
+	if !ok {
+		return nil, errors.New("email select failed")
+	}
+	return copyUser(user), nil
+}
+
+// SelectCredentials 内存存储没有缓存，与 SelectByID 相同
+func (repo *memoryUserRepo) SelectCredentials(userID int) (*model.User, error) {
+	return repo.SelectByID(userID)
+}
+
+func (repo *memoryUserRepo) Exists(username, email string) bool {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, ok := repo.active(repo.byUsername[username])
+	return ok && user.Email == email
+}
+
+func (repo *memoryUserRepo) GetRole(user *model.User) (string, error) {
+	return user.Role, nil
+}
+
+func (repo *memoryUserRepo) UpdatePassword(userID int, hashedPassword string) error {
+	return repo.update(userID, func(user *model.User) error {
+		user.Password = hashedPassword
+		return nil
+	})
+}
+
+func (repo *memoryUserRepo) MarkEmailVerified(userID int) error {
+	return repo.update(userID, func(user *model.User) error {
+		user.EmailVerified = true
+		user.EmailVerifiedAt = now()
+		return nil
+	})
+}
+
+func (repo *memoryUserRepo) UpdateRole(userID int, role string) error {
+	return repo.update(userID, func(user *model.User) error {
+		user.Role = role
+		return nil
+	})
+}
+
+func (repo *memoryUserRepo) UpdateUsername(userID int, username string) error {
+	return repo.update(userID, func(user *model.User) error {
+		if user.Username == username {
+			return nil
+		}
+		if _, ok := repo.byUsername[username]; ok {
+			return errors.New("user already exists")
+		}
+		delete(repo.byUsername, user.Username)
+		repo.byUsername[username] = userID
+		user.Username = username
+		return nil
+	})
+}
+
+func (repo *memoryUserRepo) UpdateEmail(userID int, email string) error {
+	return repo.update(userID, func(user *model.User) error {
+		if id, ok := repo.byEmail[email]; ok && id != userID {
+			return errors.New("email already exists")
+		}
+		delete(repo.byEmail, user.Email)
+		repo.byEmail[email] = userID
+		user.Email = email
+		user.EmailVerified = true
+		user.EmailVerifiedAt = now()
+		return nil
+	})
+}
+
+func (repo *memoryUserRepo) SetDisabled(userID int, disabled bool) error {
+	return repo.update(userID, func(user *model.User) error {
+		user.DisabledAt = nil
+		if disabled {
+			user.DisabledAt = now()
+		}
+		return nil
+	})
+}
+
+func (repo *memoryUserRepo) ListUsers(filter model.UserFilter) ([]model.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	domain := strings.ToLower("@" + filter.EmailDomain)
+	users := make([]model.User, 0)
+	for _, user := range repo.users {
+		if user.DeletedAt.Valid || user.UserID <= filter.Cursor {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if filter.EmailDomain != "" && !strings.HasSuffix(strings.ToLower(user.Email), domain) {
+			continue
+		}
+		if !filter.CreatedAfter.IsZero() && user.CreatedAt.Before(filter.CreatedAfter) {
+			continue
+		}
+		if !filter.CreatedBefore.IsZero() && !user.CreatedAt.Before(filter.CreatedBefore) {
+			continue
+		}
+		users = append(users, *copyUser(user))
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	if filter.Limit > 0 && len(users) > filter.Limit {
+		users = users[:filter.Limit]
+	}
+	return users, nil
+}
+
+func (repo *memoryUserRepo) SoftDelete(userID int) error {
+	return repo.update(userID, func(user *model.User) error {
+		user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		return nil
+	})
+}
+
+func (repo *memoryUserRepo) Restore(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[userID]
+	if !ok || !user.DeletedAt.Valid {
+		return errors.New("user not found")
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (repo *memoryUserRepo) ListDeletedBefore(t time.Time) ([]int, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var userIDs []int
+	for _, user := range repo.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(t) {
+			userIDs = append(userIDs, user.UserID)
+		}
+	}
+	sort.Ints(userIDs)
+	return userIDs, nil
+}
+
+func (repo *memoryUserRepo) Purge(userID int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.users[userID]
+	if !ok {
+		return nil
+	}
+	delete(repo.byUsername, user.Username)
+	delete(repo.byEmail, user.Email)
+	delete(repo.users, userID)
+	return nil
+}
+
+// active 未注销的用户，调用方需持有锁
+func (repo *memoryUserRepo) active(userID int) (*model.User, bool) {
+	user, ok := repo.users[userID]
+	if !ok || user.DeletedAt.Valid {
+		return nil, false
+	}
+	return user, true
+}
+
+// update 在写锁内修改未注销的用户
+func (repo *memoryUserRepo) update(userID int, fn func(user *model.User) error) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, ok := repo.active(userID)
+	if !ok {
+		return errors.New("user not found")
+	}
+	updated := copyUser(user)
+	if err := fn(updated); err != nil {
+		return err
+	}
+	repo.users[userID] = updated
+	return nil
+}
+
+func copyUser(user *model.User) *model.User {
+	c := *user
+	c.EmailVerifiedAt = timePtr(user.EmailVerifiedAt)
+	c.DisabledAt = timePtr(user.DisabledAt)
+	return &c
+}
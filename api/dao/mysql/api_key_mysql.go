@@ -0,0 +1,131 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/api/dao/cache"
+	"GoGin/internal/model"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type mysqlAPIKeyRepo struct {
+	db    *gorm.DB
+	cache cache.Cache
+}
+
+func NewMysqlAPIKeyRepo(db *gorm.DB, cache cache.Cache) dao.APIKeyRepository {
+	err := db.AutoMigrate(&model.APIKey{})
+	if err != nil {
+		log.Fatal("Failed to migrate api key table:", err)
+	}
+
+	return &mysqlAPIKeyRepo{
+		db:    db,
+		cache: cache,
+	}
+}
+
+func (repo *mysqlAPIKeyRepo) CreateAPIKey(key *model.APIKey) error {
+	if err := repo.db.Create(key).Error; err != nil {
+		return errors.New("api key create failed")
+	}
+	return nil
+}
+
+func (repo *mysqlAPIKeyRepo) GetAPIKeyByHash(keyHash string) (*model.APIKey, error) {
+	// 尝试从缓存获取
+	if repo.cache != nil {
+		cacheKey := fmt.Sprintf("apikey:%s", keyHash)
+		var key model.APIKey
+		if err := repo.cache.Get(cacheKey, &key); err == nil && key.ID != 0 {
+			return &key, nil
+		}
+	}
+
+	// 数据库
+	var key model.APIKey
+	if err := repo.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, errors.New("api key not found")
+	}
+
+	// 写入缓存，吊销时删除；last_used_at 以数据库为准
+	if repo.cache != nil && key.RevokedAt == nil {
+		cacheKey := fmt.Sprintf("apikey:%s", keyHash)
+		_ = repo.cache.Set(cacheKey, &key, repo.cache.RandExp(5*time.Minute))
+	}
+	return &key, nil
+}
+
+func (repo *mysqlAPIKeyRepo) ListUserAPIKeys(userID int) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	if err := repo.db.Where("user_id = ?", userID).Order("api_key_id DESC").Find(&keys).Error; err != nil {
+		return nil, errors.New("api key select failed")
+	}
+	return keys, nil
+}
+
+func (repo *mysqlAPIKeyRepo) RevokeAPIKey(userID, keyID int) error {
+	var key model.APIKey
+	if err := repo.db.Where("api_key_id = ? AND user_id = ?", keyID, userID).First(&key).Error; err != nil {
+		return errors.New("api key not found")
+	}
+
+	if err := repo.db.Model(&model.APIKey{}).
+		Where("api_key_id = ? AND revoked_at IS NULL", keyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return errors.New("api key revoke failed")
+	}
+
+	// 写后删除
+	if repo.cache != nil {
+		if err := repo.cache.Clean(fmt.Sprintf("apikey:%s", key.KeyHash)); err != nil {
+			return errors.New("cache clean failed")
+		}
+	}
+	return nil
+}
+
+func (repo *mysqlAPIKeyRepo) DeleteUserAPIKeys(userID int) error {
+	var keyHashes []string
+	if err := repo.db.Model(&model.APIKey{}).
+		Where("user_id = ?", userID).
+		Pluck("key_hash", &keyHashes).Error; err != nil {
+		return errors.New("api key select failed")
+	}
+	if err := repo.db.Where("user_id = ?", userID).Delete(&model.APIKey{}).Error; err != nil {
+		return errors.New("api key delete failed")
+	}
+
+	// 写后删除
+	if repo.cache != nil && len(keyHashes) > 0 {
+		keys := make([]string, 0, len(keyHashes))
+		for _, hash := range keyHashes {
+			keys = append(keys, fmt.Sprintf("apikey:%s", hash))
+		}
+		if err := repo.cache.Clean(keys...); err != nil {
+			return errors.New("cache clean failed")
+		}
+	}
+	return nil
+}
+
+func (repo *mysqlAPIKeyRepo) TouchAPIKey(keyID int, usedAt time.Time) error {
+	// 每个 Key 每分钟最多写一次数据库
+	if repo.cache != nil {
+		n, err := repo.cache.Incr(fmt.Sprintf("apikey:touch:%d", keyID), time.Minute)
+		if err == nil && n > 1 {
+			return nil
+		}
+	}
+
+	if err := repo.db.Model(&model.APIKey{}).
+		Where("api_key_id = ?", keyID).
+		Update("last_used_at", usedAt).Error; err != nil {
+		return errors.New("api key update failed")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+type mysqlAuditRepo struct {
+	db *gorm.DB
+}
+
+func NewMysqlAuditRepo(db *gorm.DB) dao.AuditRepository {
+	err := db.AutoMigrate(&model.AuditEntry{})
+	if err != nil {
+		log.Fatal("Failed to migrate audit table:", err)
+	}
+
+	return &mysqlAuditRepo{
+		db: db,
+	}
+}
+
+func (repo *mysqlAuditRepo) AppendAudit(entry *model.AuditEntry) error {
+	if err := repo.db.Create(entry).Error; err != nil {
+		return errors.New("audit append failed")
+	}
+	return nil
+}
+
+func (repo *mysqlAuditRepo) ListAudit(filter model.AuditFilter) ([]model.AuditEntry, error) {
+	query := repo.db.Model(&model.AuditEntry{})
+	if filter.Cursor > 0 {
+		query = query.Where("audit_id < ?", filter.Cursor)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var entries []model.AuditEntry
+	if err := query.Order("audit_id DESC").Limit(filter.Limit).Find(&entries).Error; err != nil {
+		return nil, errors.New("audit select failed")
+	}
+	return entries, nil
+}
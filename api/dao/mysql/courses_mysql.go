@@ -12,12 +12,18 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	courseLoadOptions     = cache.LoadOptions{TTL: 5 * time.Minute, NegativeTTL: time.Minute}
+	courseListLoadOptions = cache.LoadOptions{TTL: 2 * time.Minute}
+	enrollmentLoadOptions = cache.LoadOptions{TTL: 2 * time.Minute}
+)
+
 type mysqlCourseRepo struct {
 	db    *gorm.DB
-	cache *cache.RedisClient
+	cache cache.Cache
 }
 
-func NewMysqlCourseRepo(db *gorm.DB, cache *cache.RedisClient) dao.CourseRepository {
+func NewMysqlCourseRepo(db *gorm.DB, cache cache.Cache) dao.CourseRepository {
 	err := db.AutoMigrate(&model.Student{}, &model.Course{})
 	if err != nil {
 		log.Fatal("Failed to migrate student & course table:", err)
@@ -36,11 +42,12 @@ func NewMysqlCourseRepo(db *gorm.DB, cache *cache.RedisClient) dao.CourseReposit
 func (repo *mysqlCourseRepo) PickCourse(StudentID, CourseID int) error {
 	// 分布式锁
 	if repo.cache != nil {
-		lockKey := fmt.Sprintf("lock:pick:%d:%d", StudentID, CourseID)
-		if success, _ := repo.cache.Lock(lockKey, 5*time.Second); !success {
+		lockKey := fmt.Sprintf("pick:%d:%d", StudentID, CourseID)
+		lock, err := repo.cache.TryLock(lockKey, 5*time.Second)
+		if err != nil {
 			return errors.New("system busy, please try again")
 		}
-		defer repo.cache.Unlock(lockKey)
+		defer lock.Release()
 	}
 
 	err := repo.db.Transaction(func(tx *gorm.DB) error {
@@ -116,11 +123,12 @@ func (repo *mysqlCourseRepo) PickCourse(StudentID, CourseID int) error {
 func (repo *mysqlCourseRepo) DropCourse(StudentID, CourseID int) error {
 	// 分布式锁
 	if repo.cache != nil {
-		lockKey := fmt.Sprintf("lock:drop:%d:%d", StudentID, CourseID)
-		if success, _ := repo.cache.Lock(lockKey, 5*time.Second); !success {
+		lockKey := fmt.Sprintf("drop:%d:%d", StudentID, CourseID)
+		lock, err := repo.cache.TryLock(lockKey, 5*time.Second)
+		if err != nil {
 			return errors.New("system busy, please try again")
 		}
-		defer repo.cache.Unlock(lockKey)
+		defer lock.Release()
 	}
 
 	err := repo.db.Transaction(func(tx *gorm.DB) error {
@@ -170,66 +178,28 @@ func (repo *mysqlCourseRepo) DropCourse(StudentID, CourseID int) error {
 }
 
 func (repo *mysqlCourseRepo) CheckEnrollment(studentID int) ([]model.Enrollment, error) {
-	// 尝试从缓存获取
-	if repo.cache != nil {
-		cacheKey := fmt.Sprintf("enroll:student:%d", studentID)
-		var enrollments []model.Enrollment
-		if err := repo.cache.Get(cacheKey, &enrollments); err == nil {
-			return enrollments, nil
-		}
-	}
-
-	// 数据库
-	var enrollment []model.Enrollment
-	if err := repo.db.Where("student_id = ?", studentID).First(&enrollment).Error; err != nil {
+	key := fmt.Sprintf("enroll:student:%d", studentID)
+	enrollments, err := cache.GetOrLoad(repo.cache, key, enrollmentLoadOptions, func() ([]model.Enrollment, error) {
+		enrollments := []model.Enrollment{}
+		err := repo.db.Preload("Course").Where("student_id = ?", studentID).Find(&enrollments).Error
+		return enrollments, err
+	})
+	if err != nil {
 		return nil, errors.New("enrollment select failed")
 	}
-
-	// 写入缓存
-	if repo.cache != nil {
-		cacheKey := fmt.Sprintf("enroll:student:%d", studentID)
-		// 使用分布式锁
-		lockKey := fmt.Sprintf("lock:enroll:student:%d", studentID)
-		if success, _ := repo.cache.Lock(lockKey, 10*time.Second); success {
-			defer repo.cache.Unlock(lockKey)
-			err := repo.cache.Set(cacheKey, enrollment, repo.cache.RandExp(2*time.Minute))
-			if err != nil {
-				return nil, errors.New("cache set failed")
-			}
-		}
-	}
-
-	return enrollment, nil
+	return enrollments, nil
 }
 
 func (repo *mysqlCourseRepo) CheckInfo() ([]model.Course, error) {
-	// 尝试从缓存获取
-	if repo.cache != nil {
-		var courses []model.Course
-		if err := repo.cache.Get("course:all", &courses); err == nil {
-			return courses, nil
-		}
-	}
-
-	//数据库
-	var course []model.Course
-	if err := repo.db.Find(&course).Error; err != nil {
+	courses, err := cache.GetOrLoad(repo.cache, "course:all", courseListLoadOptions, func() ([]model.Course, error) {
+		courses := []model.Course{}
+		err := repo.db.Find(&courses).Error
+		return courses, err
+	})
+	if err != nil {
 		return nil, errors.New("course select failed")
 	}
-
-	// 写入缓存
-	if repo.cache != nil {
-		// 使用分布式锁
-		if success, _ := repo.cache.Lock("lock:course:all", 10*time.Second); success {
-			defer repo.cache.Unlock("lock:course:all")
-			err := repo.cache.Set("course:all", course, repo.cache.RandExp(2*time.Minute))
-			if err != nil {
-				return nil, errors.New("cache set failed")
-			}
-		}
-	}
-
-	return course, nil
+	return courses, nil
 }
 
 func (repo *mysqlCourseRepo) AddCourse(Course model.Course) error {
@@ -243,9 +213,9 @@ func (repo *mysqlCourseRepo) AddCourse(Course model.Course) error {
 		if err != nil {
 			return errors.New("cache clean failed")
 		}
-		// 缓存新创建的课程
+		// 缓存新创建的课程，同时覆盖可能存在的空值缓存
 		courseKey := fmt.Sprintf("course:%d", Course.ID)
-		err = repo.cache.Set(courseKey, Course, repo.cache.RandExp(5*time.Minute))
+		err = cache.Store(repo.cache, courseKey, Course, courseLoadOptions.TTL)
 		if err != nil {
 			return errors.New("cache set failed")
 		}
@@ -255,33 +225,67 @@ func (repo *mysqlCourseRepo) AddCourse(Course model.Course) error {
 }
 
 func (repo *mysqlCourseRepo) CheckCourse(courseID int) (model.Course, error) {
-	// 尝试从缓存获取
-	if repo.cache != nil {
-		cacheKey := fmt.Sprintf("course:%d", courseID)
+	key := fmt.Sprintf("course:%d", courseID)
+	course, err := cache.GetOrLoad(repo.cache, key, courseLoadOptions, func() (model.Course, error) {
 		var course model.Course
-		if err := repo.cache.Get(cacheKey, &course); err == nil {
-			return course, nil
+		err := repo.db.First(&course, courseID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return course, cache.ErrNotFound
 		}
+		return course, err
+	})
+	if err != nil {
+		return model.Course{}, errors.New("course not found")
 	}
+	return course, nil
+}
 
-	//数据库
-	var course model.Course
-	if err := repo.db.First(&course, courseID).Error; err != nil {
-		return model.Course{}, errors.New("course not found")
+func (repo *mysqlCourseRepo) DropAllEnrollments(studentID int) error {
+	var courseIDs []int
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var enrollments []model.Enrollment
+		if err := tx.Where("student_id = ?", studentID).Find(&enrollments).Error; err != nil {
+			return errors.New("enrollment select failed")
+		}
+		if len(enrollments) == 0 {
+			return nil
+		}
+
+		//删除
+		if err := tx.Where("student_id = ?", studentID).Delete(&model.Enrollment{}).Error; err != nil {
+			return errors.New("delete failed")
+		}
+
+		//更新人数，重复记录按次数扣减
+		counts := make(map[int]int)
+		for _, enrollment := range enrollments {
+			if counts[enrollment.CourseID] == 0 {
+				courseIDs = append(courseIDs, enrollment.CourseID)
+			}
+			counts[enrollment.CourseID]++
+		}
+		for _, courseID := range courseIDs {
+			if err := tx.Model(&model.Course{}).
+				Where("course_id = ?", courseID).
+				Update("enroll", gorm.Expr("GREATEST(enroll - ?, 0)", counts[courseID])).Error; err != nil {
+				return errors.New("update failed")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// 写入缓存
+	// 写后删除
 	if repo.cache != nil {
-		cacheKey := fmt.Sprintf("course:%d", courseID)
-		// 使用分布式锁
-		lockKey := fmt.Sprintf("lock:course:%d", courseID)
-		if success, _ := repo.cache.Lock(lockKey, 10*time.Second); success {
-			defer repo.cache.Unlock(lockKey)
-			err := repo.cache.Set(cacheKey, course, repo.cache.RandExp(5*time.Minute))
-			if err != nil {
-				return model.Course{}, errors.New("cache set failed")
-			}
+		keys := []string{"course:all", fmt.Sprintf("enroll:student:%d", studentID)}
+		for _, courseID := range courseIDs {
+			keys = append(keys, fmt.Sprintf("course:%d", courseID))
+		}
+		if err := repo.cache.Clean(keys...); err != nil {
+			return errors.New("cache clean failed")
 		}
 	}
-	return course, nil
+	return nil
 }
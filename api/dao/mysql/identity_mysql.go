@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+type mysqlIdentityRepo struct {
+	db *gorm.DB
+}
+
+func NewMysqlIdentityRepo(db *gorm.DB) dao.IdentityRepository {
+	err := db.AutoMigrate(&model.UserIdentity{})
+	if err != nil {
+		log.Fatal("Failed to migrate user identity table:", err)
+	}
+
+	return &mysqlIdentityRepo{
+		db: db,
+	}
+}
+
+func (repo *mysqlIdentityRepo) CreateIdentity(identity *model.UserIdentity) error {
+	if err := repo.db.Create(identity).Error; err != nil {
+		return errors.New("identity already linked")
+	}
+	return nil
+}
+
+func (repo *mysqlIdentityRepo) GetIdentity(provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	if err := repo.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, errors.New("identity not found")
+	}
+	return &identity, nil
+}
+
+func (repo *mysqlIdentityRepo) ListUserIdentities(userID int) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	if err := repo.db.Where("user_id = ?", userID).Order("identity_id ASC").Find(&identities).Error; err != nil {
+		return nil, errors.New("identity select failed")
+	}
+	return identities, nil
+}
+
+func (repo *mysqlIdentityRepo) DeleteIdentity(userID, identityID int) error {
+	result := repo.db.Where("identity_id = ? AND user_id = ?", identityID, userID).Delete(&model.UserIdentity{})
+	if result.Error != nil {
+		return errors.New("identity delete failed")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("identity not found")
+	}
+	return nil
+}
+
+func (repo *mysqlIdentityRepo) DeleteUserIdentities(userID int) error {
+	if err := repo.db.Where("user_id = ?", userID).Delete(&model.UserIdentity{}).Error; err != nil {
+		return errors.New("identity delete failed")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type mysqlInvitationRepo struct {
+	db *gorm.DB
+}
+
+func NewMysqlInvitationRepo(db *gorm.DB) dao.InvitationRepository {
+	err := db.AutoMigrate(&model.Invitation{}, &model.RoleRequest{})
+	if err != nil {
+		log.Fatal("Failed to migrate invitation & role request table:", err)
+	}
+
+	return &mysqlInvitationRepo{
+		db: db,
+	}
+}
+
+func (repo *mysqlInvitationRepo) CreateInvitation(invitation *model.Invitation) error {
+	if err := repo.db.Create(invitation).Error; err != nil {
+		return errors.New("invitation create failed")
+	}
+	return nil
+}
+
+func (repo *mysqlInvitationRepo) ListInvitations() ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	if err := repo.db.Order("invitation_id DESC").Find(&invitations).Error; err != nil {
+		return nil, errors.New("invitation select failed")
+	}
+	return invitations, nil
+}
+
+func (repo *mysqlInvitationRepo) RevokeInvitation(invitationID int) error {
+	result := repo.db.Model(&model.Invitation{}).
+		Where("invitation_id = ? AND revoked_at IS NULL", invitationID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return errors.New("invitation revoke failed")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invitation not found")
+	}
+	return nil
+}
+
+func (repo *mysqlInvitationRepo) UseInvitation(codeHash string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		// 条件更新保证并发注册不会超过次数上限
+		result := tx.Model(&model.Invitation{}).
+			Where("code_hash = ? AND revoked_at IS NULL AND expires_at > ? AND uses < max_uses", codeHash, time.Now()).
+			Update("uses", gorm.Expr("uses + ?", 1))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("invitation invalid")
+		}
+		return tx.Where("code_hash = ?", codeHash).First(&invitation).Error
+	})
+	if err != nil {
+		return nil, errors.New("invitation code invalid or expired")
+	}
+	return &invitation, nil
+}
+
+func (repo *mysqlInvitationRepo) ReleaseInvitation(invitationID int) error {
+	if err := repo.db.Model(&model.Invitation{}).
+		Where("invitation_id = ? AND uses > 0", invitationID).
+		Update("uses", gorm.Expr("uses - ?", 1)).Error; err != nil {
+		return errors.New("invitation release failed")
+	}
+	return nil
+}
+
+func (repo *mysqlInvitationRepo) CreateRoleRequest(request *model.RoleRequest) error {
+	if err := repo.db.Create(request).Error; err != nil {
+		return errors.New("role request create failed")
+	}
+	return nil
+}
+
+func (repo *mysqlInvitationRepo) GetRoleRequest(requestID int) (*model.RoleRequest, error) {
+	var request model.RoleRequest
+	if err := repo.db.First(&request, requestID).Error; err != nil {
+		return nil, errors.New("role request not found")
+	}
+	return &request, nil
+}
+
+func (repo *mysqlInvitationRepo) ListRoleRequests(status string) ([]model.RoleRequest, error) {
+	query := repo.db.Order("request_id ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var requests []model.RoleRequest
+	if err := query.Find(&requests).Error; err != nil {
+		return nil, errors.New("role request select failed")
+	}
+	return requests, nil
+}
+
+func (repo *mysqlInvitationRepo) ListUserRoleRequests(userID int) ([]model.RoleRequest, error) {
+	var requests []model.RoleRequest
+	if err := repo.db.Where("user_id = ?", userID).Order("request_id DESC").Find(&requests).Error; err != nil {
+		return nil, errors.New("role request select failed")
+	}
+	return requests, nil
+}
+
+func (repo *mysqlInvitationRepo) HasPendingRoleRequest(userID int) (bool, error) {
+	var count int64
+	if err := repo.db.Model(&model.RoleRequest{}).
+		Where("user_id = ? AND status = ?", userID, model.RoleRequestPending).
+		Count(&count).Error; err != nil {
+		return false, errors.New("role request select failed")
+	}
+	return count > 0, nil
+}
+
+func (repo *mysqlInvitationRepo) DecideRoleRequest(requestID int, status string, decidedBy int, note string) error {
+	result := repo.db.Model(&model.RoleRequest{}).
+		Where("request_id = ? AND status = ?", requestID, model.RoleRequestPending).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"decided_by": decidedBy,
+			"note":       note,
+			"decided_at": time.Now(),
+		})
+	if result.Error != nil {
+		return errors.New("role request update failed")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("role request already decided")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+type mysqlRoleGrantRepo struct {
+	db *gorm.DB
+}
+
+func NewMysqlRoleGrantRepo(db *gorm.DB) dao.RoleGrantRepository {
+	err := db.AutoMigrate(&model.RoleGrant{})
+	if err != nil {
+		log.Fatal("Failed to migrate role grant table:", err)
+	}
+
+	return &mysqlRoleGrantRepo{
+		db: db,
+	}
+}
+
+func (repo *mysqlRoleGrantRepo) RecordGrant(grant *model.RoleGrant) error {
+	if err := repo.db.Create(grant).Error; err != nil {
+		return errors.New("role grant record failed")
+	}
+	return nil
+}
+
+func (repo *mysqlRoleGrantRepo) ListGrants(userID int) ([]model.RoleGrant, error) {
+	query := repo.db.Order("grant_id DESC")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var grants []model.RoleGrant
+	if err := query.Find(&grants).Error; err != nil {
+		return nil, errors.New("role grant select failed")
+	}
+	return grants, nil
+}
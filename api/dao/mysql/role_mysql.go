@@ -0,0 +1,211 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/api/dao/cache"
+	"GoGin/internal/model"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type mysqlRoleRepo struct {
+	db    *gorm.DB
+	cache cache.Cache
+}
+
+func NewMysqlRoleRepo(db *gorm.DB, cache cache.Cache) dao.RoleRepository {
+	err := db.AutoMigrate(&model.Role{}, &model.Permission{})
+	if err != nil {
+		log.Fatal("Failed to migrate role & permission table:", err)
+	}
+
+	repo := &mysqlRoleRepo{
+		db:    db,
+		cache: cache,
+	}
+	if err := repo.seed(); err != nil {
+		log.Fatal("Failed to seed roles:", err)
+	}
+	return repo
+}
+
+// seed 写入内置权限和角色；已存在的内置角色补齐新增的默认权限，其余权限保持管理员的修改
+func (repo *mysqlRoleRepo) seed() error {
+	for _, permission := range model.DefaultPermissions {
+		p := permission
+		if err := repo.db.Where(model.Permission{Name: p.Name}).FirstOrCreate(&p).Error; err != nil {
+			return err
+		}
+	}
+
+	for name, permissions := range model.DefaultRoles {
+		role, err := repo.GetRole(name)
+		if err != nil {
+			if err := repo.CreateRole(&model.Role{Name: name}, permissions); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := repo.addMissingPermissions(role, permissions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMissingPermissions 为角色追加尚未拥有的权限
+func (repo *mysqlRoleRepo) addMissingPermissions(role *model.Role, permissions []string) error {
+	owned := make(map[string]bool, len(role.Permissions))
+	for _, p := range role.Permissions {
+		owned[p.Name] = true
+	}
+	missing := make([]string, 0, len(permissions))
+	for _, name := range permissions {
+		if !owned[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	perms, err := findPermissions(repo.db, missing)
+	if err != nil {
+		return err
+	}
+	if err := repo.db.Model(role).Association("Permissions").Append(perms); err != nil {
+		return errors.New("role permission update failed")
+	}
+	log.Printf("role %s: added default permissions %v", role.Name, missing)
+
+	// 写后删除
+	if repo.cache != nil {
+		if err := repo.cache.Clean(fmt.Sprintf("role:perms:%s", role.Name)); err != nil {
+			return errors.New("cache clean failed")
+		}
+	}
+	return nil
+}
+
+func (repo *mysqlRoleRepo) ListRoles() ([]model.Role, error) {
+	var roles []model.Role
+	if err := repo.db.Preload("Permissions").Find(&roles).Error; err != nil {
+		return nil, errors.New("role select failed")
+	}
+	return roles, nil
+}
+
+func (repo *mysqlRoleRepo) GetRole(name string) (*model.Role, error) {
+	var role model.Role
+	if err := repo.db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, errors.New("role not found")
+	}
+	return &role, nil
+}
+
+func (repo *mysqlRoleRepo) CreateRole(role *model.Role, permissions []string) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		perms, err := findPermissions(tx, permissions)
+		if err != nil {
+			return err
+		}
+		role.Permissions = perms
+		if err := tx.Create(role).Error; err != nil {
+			return errors.New("role create failed")
+		}
+		return nil
+	})
+}
+
+func (repo *mysqlRoleRepo) SetPermissions(roleName string, permissions []string) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var role model.Role
+		if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+			return errors.New("role not found")
+		}
+		perms, err := findPermissions(tx, permissions)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&role).Association("Permissions").Replace(perms); err != nil {
+			return errors.New("role permission update failed")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 写后删除
+	if repo.cache != nil {
+		if err := repo.cache.Clean(fmt.Sprintf("role:perms:%s", roleName)); err != nil {
+			return errors.New("cache clean failed")
+		}
+	}
+	return nil
+}
+
+func (repo *mysqlRoleRepo) ListPermissions() ([]model.Permission, error) {
+	var permissions []model.Permission
+	if err := repo.db.Find(&permissions).Error; err != nil {
+		return nil, errors.New("permission select failed")
+	}
+	return permissions, nil
+}
+
+func (repo *mysqlRoleRepo) GetPermissions(roleName string) ([]string, error) {
+	// 尝试从缓存获取
+	if repo.cache != nil {
+		cacheKey := fmt.Sprintf("role:perms:%s", roleName)
+		var permissions []string
+		if err := repo.cache.Get(cacheKey, &permissions); err == nil {
+			return permissions, nil
+		}
+	}
+
+	// 数据库
+	role, err := repo.GetRole(roleName)
+	if err != nil {
+		return nil, err
+	}
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		permissions = append(permissions, p.Name)
+	}
+
+	// 写入缓存，Redis 不可用时仍以数据库为准
+	if repo.cache != nil {
+		cacheKey := fmt.Sprintf("role:perms:%s", roleName)
+		_ = repo.cache.Set(cacheKey, permissions, repo.cache.RandExp(5*time.Minute))
+	}
+	return permissions, nil
+}
+
+// findPermissions 按名称查找权限，存在未知权限时报错
+func findPermissions(tx *gorm.DB, names []string) ([]model.Permission, error) {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	names = unique
+
+	if len(names) == 0 {
+		return []model.Permission{}, nil
+	}
+	var perms []model.Permission
+	if err := tx.Where("name IN ?", names).Find(&perms).Error; err != nil {
+		return nil, errors.New("permission select failed")
+	}
+	if len(perms) != len(names) {
+		return nil, errors.New("unknown permission")
+	}
+	return perms, nil
+}
@@ -0,0 +1,204 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/api/dao/cache"
+	"GoGin/internal/model"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// cachedSession 缓存中的会话；TokenHash 在 model.Session 中不参与序列化，这里单独保存，否则缓存命中时无法校验刷新令牌
+type cachedSession struct {
+	model.Session
+	TokenHash string `json:"token_hash"`
+}
+
+type mysqlSessionRepo struct {
+	db    *gorm.DB
+	cache cache.Cache
+}
+
+func NewMysqlSessionRepo(db *gorm.DB, cache cache.Cache) dao.SessionRepository {
+	err := db.AutoMigrate(&model.Session{})
+	if err != nil {
+		log.Fatal("Failed to migrate session table:", err)
+	}
+
+	return &mysqlSessionRepo{
+		db:    db,
+		cache: cache,
+	}
+}
+
+func (repo *mysqlSessionRepo) CreateSession(session *model.Session) error {
+	if err := repo.db.Create(session).Error; err != nil {
+		return errors.New("session create failed")
+	}
+
+	// 写入缓存，Redis 不可用时仍以数据库为准
+	if repo.cache != nil {
+		key := fmt.Sprintf("session:%s", session.SessionID)
+		_ = repo.cache.Set(key, cachedSession{Session: *session, TokenHash: session.TokenHash}, time.Until(session.ExpiresAt))
+	}
+
+	return nil
+}
+
+func (repo *mysqlSessionRepo) GetSession(sessionID string) (*model.Session, error) {
+	// 尝试从缓存获取
+	if repo.cache != nil {
+		key := fmt.Sprintf("session:%s", sessionID)
+		var cached cachedSession
+		if err := repo.cache.Get(key, &cached); err == nil && cached.TokenHash != "" {
+			session := cached.Session
+			session.TokenHash = cached.TokenHash
+			return &session, nil
+		}
+	}
+
+	// 缓存未命中或不可用，查询数据库
+	var session model.Session
+	if err := repo.db.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		return nil, errors.New("session not found")
+	}
+
+	if repo.cache != nil && !session.Revoked {
+		key := fmt.Sprintf("session:%s", sessionID)
+		_ = repo.cache.Set(key, cachedSession{Session: session, TokenHash: session.TokenHash}, time.Until(session.ExpiresAt))
+	}
+
+	return &session, nil
+}
+
+func (repo *mysqlSessionRepo) RotateSession(sessionID, oldHash, newHash string, expiresAt time.Time) error {
+	// 条件更新保证同一个旧令牌只能轮换一次
+	result := repo.db.Model(&model.Session{}).
+		Where("session_id = ? AND token_hash = ? AND revoked = ?", sessionID, oldHash, false).
+		Updates(map[string]interface{}{
+			"token_hash": newHash,
+			"expires_at": expiresAt,
+		})
+	if result.Error != nil {
+		return errors.New("session rotate failed")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("session already rotated")
+	}
+
+	// 写后删除
+	if repo.cache != nil {
+		if err := repo.cache.Clean(fmt.Sprintf("session:%s", sessionID)); err != nil {
+			return errors.New("cache clean failed")
+		}
+	}
+
+	return nil
+}
+
+func (repo *mysqlSessionRepo) RevokeSession(sessionID string) error {
+	if err := repo.db.Model(&model.Session{}).
+		Where("session_id = ?", sessionID).
+		Update("revoked", true).Error; err != nil {
+		return errors.New("session revoke failed")
+	}
+
+	// 写后删除
+	if repo.cache != nil {
+		if err := repo.cache.Clean(fmt.Sprintf("session:%s", sessionID)); err != nil {
+			return errors.New("cache clean failed")
+		}
+	}
+
+	return nil
+}
+
+func (repo *mysqlSessionRepo) RevokeUserSessions(userID int) error {
+	if err := repo.db.Model(&model.Session{}).
+		Where("user_id = ? AND revoked = ?", userID, false).
+		Update("revoked", true).Error; err != nil {
+		return errors.New("session revoke failed")
+	}
+
+	// 写后删除：清掉该用户所有未过期会话的缓存
+	if repo.cache != nil {
+		var sessionIDs []string
+		if err := repo.db.Model(&model.Session{}).
+			Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+			Pluck("session_id", &sessionIDs).Error; err != nil {
+			return errors.New("session select failed")
+		}
+		if len(sessionIDs) == 0 {
+			return nil
+		}
+		keys := make([]string, 0, len(sessionIDs))
+		for _, id := range sessionIDs {
+			keys = append(keys, fmt.Sprintf("session:%s", id))
+		}
+		if err := repo.cache.Clean(keys...); err != nil {
+			return errors.New("cache clean failed")
+		}
+	}
+
+	return nil
+}
+
+func (repo *mysqlSessionRepo) DeleteUserSessions(userID int) error {
+	var sessionIDs []string
+	if err := repo.db.Model(&model.Session{}).
+		Where("user_id = ?", userID).
+		Pluck("session_id", &sessionIDs).Error; err != nil {
+		return errors.New("session select failed")
+	}
+	if err := repo.db.Where("user_id = ?", userID).Delete(&model.Session{}).Error; err != nil {
+		return errors.New("session delete failed")
+	}
+
+	// 写后删除
+	if repo.cache != nil && len(sessionIDs) > 0 {
+		keys := make([]string, 0, len(sessionIDs))
+		for _, id := range sessionIDs {
+			keys = append(keys, fmt.Sprintf("session:%s", id))
+		}
+		if err := repo.cache.Clean(keys...); err != nil {
+			return errors.New("cache clean failed")
+		}
+	}
+	return nil
+}
+
+func (repo *mysqlSessionRepo) ListUserSessions(userID int) ([]model.Session, error) {
+	var sessions []model.Session
+	if err := repo.db.
+		Where("user_id = ? AND revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, errors.New("session select failed")
+	}
+	return sessions, nil
+}
+
+func (repo *mysqlSessionRepo) TouchSession(sessionID string, client model.ClientInfo, seenAt time.Time) error {
+	// 每个会话每分钟最多写一次数据库；缓存中的会话只用于刷新令牌，不需要删除
+	if repo.cache != nil {
+		n, err := repo.cache.Incr(fmt.Sprintf("session:touch:%s", sessionID), time.Minute)
+		if err == nil && n > 1 {
+			return nil
+		}
+	}
+
+	if err := repo.db.Model(&model.Session{}).
+		Where("session_id = ?", sessionID).
+		Updates(map[string]interface{}{
+			"last_seen_at": seenAt,
+			"ip":           client.IP,
+			"user_agent":   client.UserAgent,
+		}).Error; err != nil {
+		return errors.New("session update failed")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"GoGin/api/dao/cache"
+	"GoGin/internal/model"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 指向一个不可达的地址：dryRun 时只生成 SQL 不执行，写入总是成功、查询返回零值；
+// 否则每条语句都会因连接失败而出错
+func newTestDB(t *testing.T, dryRun bool) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "test:test@tcp(127.0.0.1:1)/test?parseTime=true&timeout=1s",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: dryRun, SkipDefaultTransaction: true, DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	return db
+}
+
+// 刷新时要比较令牌哈希，缓存命中的会话必须带着哈希
+func TestGetSessionFromCacheKeepsTokenHash(t *testing.T) {
+	repo := &mysqlSessionRepo{db: newTestDB(t, true), cache: cache.NewMemoryCache(100)}
+	session := &model.Session{SessionID: "s1", UserID: 1, TokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.CreateSession(session); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	// 数据库只返回零值，带着哈希和用户的会话只能来自缓存
+	got, err := repo.GetSession("s1")
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if got.TokenHash != "hash" || got.UserID != 1 {
+		t.Fatalf("GetSession = %+v, want token hash from cache", got)
+	}
+}
+
+// 缓存中没有哈希的条目视为未命中，不能返回空哈希的会话
+func TestGetSessionIgnoresCachedSessionWithoutHash(t *testing.T) {
+	c := cache.NewMemoryCache(100)
+	repo := &mysqlSessionRepo{db: newTestDB(t, false), cache: c}
+	if err := c.Set("session:s1", model.Session{SessionID: "s1", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if got, err := repo.GetSession("s1"); err == nil {
+		t.Fatalf("GetSession = %+v, want the database error instead of a session without hash", got)
+	}
+}
@@ -12,12 +12,14 @@ import (
 	"gorm.io/gorm"
 )
 
+var todoLoadOptions = cache.LoadOptions{TTL: 2 * time.Minute}
+
 type mysqlTodoRepo struct {
 	db    *gorm.DB
-	cache *cache.RedisClient
+	cache cache.Cache
 }
 
-func NewMysqlTodoRepo(db *gorm.DB, cache *cache.RedisClient) dao.TodoRepository {
+func NewMysqlTodoRepo(db *gorm.DB, cache cache.Cache) dao.TodoRepository {
 	err := db.AutoMigrate(&model.TodoTask{})
 	if err != nil {
 		log.Fatal("Failed to migrate student & course table:", err)
@@ -93,51 +95,59 @@ func (repo *mysqlTodoRepo) FinishTodoTask(taskID int) error {
 }
 
 func (repo *mysqlTodoRepo) CheckTodoTask(userID int) ([]model.TodoTask, []model.TodoTask, error) {
-	// 尝试从缓存获取
-	if repo.cache != nil {
-		todosKey := fmt.Sprintf("todo:user:%d:todos", userID)
-		donesKey := fmt.Sprintf("todo:user:%d:dones", userID)
-
-		var todos []model.TodoTask
-		var dones []model.TodoTask
+	todos, err := repo.loadTasks(fmt.Sprintf("todo:user:%d:todos", userID), userID, false)
+	if err != nil {
+		return nil, nil, errors.New("failed to check task")
+	}
+	dones, err := repo.loadTasks(fmt.Sprintf("todo:user:%d:dones", userID), userID, true)
+	if err != nil {
+		return nil, nil, errors.New("failed to check task")
+	}
+	return todos, dones, nil
+}
 
-		todosErr := repo.cache.Get(todosKey, &todos)
-		donesErr := repo.cache.Get(donesKey, &dones)
+// loadTasks 未归档的待办或已完成事项，带缓存
+func (repo *mysqlTodoRepo) loadTasks(key string, userID int, completed bool) ([]model.TodoTask, error) {
+	return cache.GetOrLoad(repo.cache, key, todoLoadOptions, func() ([]model.TodoTask, error) {
+		tasks := []model.TodoTask{}
+		err := repo.db.Where("user_id = ? AND completed = ? AND archived_at IS NULL", userID, completed).Find(&tasks).Error
+		return tasks, err
+	})
+}
 
-		if todosErr == nil && donesErr == nil {
-			return todos, dones, nil
-		}
+func (repo *mysqlTodoRepo) ArchiveUserTodos(userID int) error {
+	if err := repo.db.Model(&model.TodoTask{}).
+		Where("user_id = ? AND archived_at IS NULL", userID).
+		Update("archived_at", time.Now()).Error; err != nil {
+		return errors.New("failed to archive tasks")
 	}
+	return repo.cleanUserTodos(userID)
+}
 
-	//缓存未命中，查询数据库
-	var todos []model.TodoTask
-	var dones []model.TodoTask
-	if err := repo.db.Where("user_id = ? AND complete = ?", userID, false).Find(&todos).Error; err != nil {
-		return nil, nil, errors.New("failed to check task")
+func (repo *mysqlTodoRepo) UnarchiveUserTodos(userID int) error {
+	if err := repo.db.Model(&model.TodoTask{}).
+		Where("user_id = ? AND archived_at IS NOT NULL", userID).
+		Update("archived_at", nil).Error; err != nil {
+		return errors.New("failed to unarchive tasks")
 	}
-	if err := repo.db.Where("user_id = ? AND complete = ?", userID, true).Where("complete = ?", true).Find(&dones).Error; err != nil {
-		return nil, nil, errors.New("failed to check task")
+	return repo.cleanUserTodos(userID)
+}
+
+func (repo *mysqlTodoRepo) DeleteUserTodos(userID int) error {
+	if err := repo.db.Where("user_id = ?", userID).Delete(&model.TodoTask{}).Error; err != nil {
+		return errors.New("failed to delete tasks")
 	}
+	return repo.cleanUserTodos(userID)
+}
 
-	// 写入缓存
-	if repo.cache != nil {
-		todosKey := fmt.Sprintf("todo:user:%d:todos", userID)
-		donesKey := fmt.Sprintf("todo:user:%d:dones", userID)
-
-		// 使用分布式锁防止缓存击穿
-		lockKey := fmt.Sprintf("lock:todo:user:%d", userID)
-		if success, _ := repo.cache.Lock(lockKey, 10*time.Second); success {
-			defer repo.cache.Unlock(lockKey)
-
-			err := repo.cache.Set(todosKey, todos, repo.cache.RandExp(2*time.Minute))
-			if err != nil {
-				return nil, nil, errors.New("failed to write cache")
-			}
-			err = repo.cache.Set(donesKey, dones, repo.cache.RandExp(2*time.Minute))
-			if err != nil {
-				return nil, nil, errors.New("failed to write cache")
-			}
-		}
+func (repo *mysqlTodoRepo) cleanUserTodos(userID int) error {
+	if repo.cache == nil {
+		return nil
 	}
-	return todos, dones, nil
+	todosKey := fmt.Sprintf("todo:user:%d:todos", userID)
+	donesKey := fmt.Sprintf("todo:user:%d:dones", userID)
+	if err := repo.cache.Clean(todosKey, donesKey); err != nil {
+		return errors.New("failed to clean redis key: dones,todos")
+	}
+	return nil
 }
@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type mysqlTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewMysqlTokenRepo(db *gorm.DB) dao.TokenRepository {
+	err := db.AutoMigrate(&model.OneTimeToken{})
+	if err != nil {
+		log.Fatal("Failed to migrate one-time token table:", err)
+	}
+
+	return &mysqlTokenRepo{
+		db: db,
+	}
+}
+
+func (repo *mysqlTokenRepo) CreateToken(token *model.OneTimeToken) error {
+	if err := repo.db.Create(token).Error; err != nil {
+		return errors.New("token create failed")
+	}
+	return nil
+}
+
+func (repo *mysqlTokenRepo) ConsumeToken(purpose, tokenHash string) (*model.OneTimeToken, error) {
+	var token model.OneTimeToken
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// 条件更新保证令牌只能被使用一次
+		result := tx.Model(&model.OneTimeToken{}).
+			Where("purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", purpose, tokenHash, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("token invalid or expired")
+		}
+
+		return tx.Where("token_hash = ?", tokenHash).First(&token).Error
+	})
+	if err != nil {
+		return nil, errors.New("token invalid or expired")
+	}
+
+	return &token, nil
+}
+
+func (repo *mysqlTokenRepo) DeleteUserTokens(userID int, purpose string) error {
+	if err := repo.db.Where("user_id = ? AND purpose = ?", userID, purpose).
+		Delete(&model.OneTimeToken{}).Error; err != nil {
+		return errors.New("token delete failed")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package mysql
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mysqlTwoFactorRepo struct {
+	db *gorm.DB
+}
+
+func NewMysqlTwoFactorRepo(db *gorm.DB) dao.TwoFactorRepository {
+	err := db.AutoMigrate(&model.TwoFactor{}, &model.RecoveryCode{})
+	if err != nil {
+		log.Fatal("Failed to migrate two-factor tables:", err)
+	}
+
+	return &mysqlTwoFactorRepo{
+		db: db,
+	}
+}
+
+func (repo *mysqlTwoFactorRepo) GetTwoFactor(userID int) (*model.TwoFactor, error) {
+	var twoFactor model.TwoFactor
+	if err := repo.db.Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dao.ErrTwoFactorNotFound
+		}
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (repo *mysqlTwoFactorRepo) SaveTwoFactor(twoFactor *model.TwoFactor) error {
+	err := repo.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.TwoFactor
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", twoFactor.UserID).First(&existing).Error
+		if err == nil && existing.Enabled {
+			return errors.New("two-factor already enabled")
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return tx.Save(twoFactor).Error
+	})
+	if err != nil {
+		return errors.New("two-factor save failed: " + err.Error())
+	}
+	return nil
+}
+
+func (repo *mysqlTwoFactorRepo) EnableTwoFactor(userID int) error {
+	if err := repo.db.Model(&model.TwoFactor{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"enabled":      true,
+			"confirmed_at": time.Now(),
+		}).Error; err != nil {
+		return errors.New("two-factor enable failed")
+	}
+	return nil
+}
+
+func (repo *mysqlTwoFactorRepo) DisableTwoFactor(userID int) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.TwoFactor{}).Error; err != nil {
+			return errors.New("two-factor disable failed")
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return errors.New("recovery code delete failed")
+		}
+		return nil
+	})
+}
+
+func (repo *mysqlTwoFactorRepo) UseStep(userID int, step int64) (bool, error) {
+	result := repo.db.Model(&model.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, errors.New("two-factor update failed")
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (repo *mysqlTwoFactorRepo) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return errors.New("recovery code delete failed")
+		}
+
+		codes := make([]model.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, model.RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return errors.New("recovery code create failed")
+		}
+		return nil
+	})
+}
+
+func (repo *mysqlTwoFactorRepo) UseRecoveryCode(userID int, codeHash string) error {
+	result := repo.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return errors.New("recovery code update failed")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invalid recovery code")
+	}
+	return nil
+}
@@ -7,17 +7,20 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var userLoadOptions = cache.LoadOptions{TTL: 5 * time.Minute, NegativeTTL: time.Minute}
+
 type mysqlUserRepo struct {
 	db    *gorm.DB
-	cache *cache.RedisClient
+	cache cache.Cache
 }
 
-func NewMysqlUserRepo(db *gorm.DB, cache *cache.RedisClient) dao.UserRepository {
+func NewMysqlUserRepo(db *gorm.DB, cache cache.Cache) dao.UserRepository {
 	err := db.AutoMigrate(&model.User{})
 	if err != nil {
 		log.Fatal("Failed to migrate user table:", err)
@@ -32,7 +35,7 @@ func NewMysqlUserRepo(db *gorm.DB, cache *cache.RedisClient) dao.UserRepository
 func (repo *mysqlUserRepo) AddUser(user *model.User) error {
 	//检查用户名是否存在
 	var existsUsernameCount int64
-	repo.db.Model(&model.User{}).
+	repo.db.Unscoped().Model(&model.User{}).
 		Where("username = ?", user.Username).
 		Count(&existsUsernameCount)
 	if existsUsernameCount > 0 {
@@ -41,7 +44,7 @@ func (repo *mysqlUserRepo) AddUser(user *model.User) error {
 
 	//检查邮箱是否存在
 	var existsEmailCount int64
-	repo.db.Model(&model.User{}).
+	repo.db.Unscoped().Model(&model.User{}).
 		Where("email = ?", user.Email).
 		Count(&existsEmailCount)
 	if existsEmailCount > 0 {
@@ -52,162 +55,289 @@ func (repo *mysqlUserRepo) AddUser(user *model.User) error {
 	if err.Error != nil {
 		return err.Error
 	}
-	//写入缓存
-	if repo.cache != nil {
-		userCacheKey := fmt.Sprintf("user:id:%d", user.UserID)
-		err := repo.cache.Set(userCacheKey, user, repo.cache.RandExp(5*time.Minute))
-		if err != nil {
-			return errors.New("set cache failed")
-		}
 
-		usernameCacheKey := fmt.Sprintf("user:username:%s", user.Username)
-		err = repo.cache.Set(usernameCacheKey, user, repo.cache.RandExp(5*time.Minute))
-		if err != nil {
-			return errors.New("set cache failed")
-		}
+	// 写后删除：清掉新用户名和邮箱可能存在的空值缓存
+	return repo.cleanUserCache(user)
+}
 
-		emailCacheKey := fmt.Sprintf("user:email:%s", user.Email)
-		err = repo.cache.Set(emailCacheKey, user, repo.cache.RandExp(5*time.Minute))
-		if err != nil {
-			return errors.New("set cache failed")
-		}
+func (repo *mysqlUserRepo) SelectByID(userID int) (*model.User, error) {
+	user, err := repo.load(fmt.Sprintf("user:id:%d", userID), func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userID)
+	})
+	if err != nil {
+		return nil, errors.New("user select failed")
 	}
-
-	return nil
+	return user, nil
 }
 
 func (repo *mysqlUserRepo) SelectByUsername(username string) (*model.User, error) {
-	//尝试访问缓存
-	if repo.cache == nil {
-		key := fmt.Sprintf("user:username:%s", username)
-		var user model.User
-		if err := repo.cache.Get(key, &user); err == nil {
-			return &user, nil
-		}
+	user, err := repo.load(fmt.Sprintf("user:username:%s", username), func(db *gorm.DB) *gorm.DB {
+		return db.Where("username = ?", username)
+	})
+	if err != nil {
+		return nil, errors.New("username select failed")
 	}
+	return user, nil
+}
 
-	//缓存未命中，查询数据库
-	var user model.User
-	err := repo.db.Where("username = ?", username).First(&user).Error
+func (repo *mysqlUserRepo) SelectByEmail(email string) (*model.User, error) {
+	user, err := repo.load(fmt.Sprintf("user:email:%s", email), func(db *gorm.DB) *gorm.DB {
+		return db.Where("email = ?", email)
+	})
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			//缓存空值防止缓存穿透
-			if repo.cache != nil {
-				key := fmt.Sprintf("user:username:%s", username)
-				fakeUser := model.User{}
-				err := repo.cache.Set(key, fakeUser, repo.cache.RandExp(5*time.Minute))
-				if err != nil {
-					return nil, errors.New("set cache failed")
-				}
-			}
-			return nil, errors.New("username select failed")
-		}
-		return nil, errors.New("username select failed")
+		return nil, errors.New("email select failed")
 	}
+	return user, nil
+}
 
-	//写入缓存
-	if repo.cache != nil {
-		//分布式锁
-		lockKey := fmt.Sprintf("lock:user:username:%s", user.Username)
-		if suc, _ := repo.cache.Lock(lockKey, 10*time.Second); suc {
-			defer repo.cache.Unlock(lockKey)
-
-			userCacheKey := fmt.Sprintf("user:id:%d", user.UserID)
-			err := repo.cache.Set(userCacheKey, &user, repo.cache.RandExp(5*time.Minute))
-			if err != nil {
-				return nil, errors.New("set cache failed")
-			}
-
-			usernameCacheKey := fmt.Sprintf("user:username:%s", user.Username)
-			err = repo.cache.Set(usernameCacheKey, &user, repo.cache.RandExp(5*time.Minute))
-			if err != nil {
-				return nil, errors.New("set cache failed")
-			}
-
-			emailCacheKey := fmt.Sprintf("user:email:%s", user.Email)
-			err = repo.cache.Set(emailCacheKey, &user, repo.cache.RandExp(5*time.Minute))
-			if err != nil {
-				return nil, errors.New("set cache failed")
-			}
-		}
+func (repo *mysqlUserRepo) SelectCredentials(userID int) (*model.User, error) {
+	var user model.User
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user select failed")
 	}
 	return &user, nil
 }
 
-func (repo *mysqlUserRepo) SelectByEmail(email string) (*model.User, error) {
-	// 缓存
-	if repo.cache != nil {
-		cacheKey := fmt.Sprintf("user:email:%s", email)
+func (repo *mysqlUserRepo) Exists(username, email string) bool {
+	user, err := repo.SelectByUsername(username)
+	return err == nil && user.Email == email
+}
+
+// load 按条件查询单个用户并缓存，不存在时写入空值缓存防止缓存穿透
+func (repo *mysqlUserRepo) load(key string, where func(db *gorm.DB) *gorm.DB) (*model.User, error) {
+	user, err := cache.GetOrLoad(repo.cache, key, userLoadOptions, func() (model.User, error) {
 		var user model.User
-		if err := repo.cache.Get(cacheKey, &user); err == nil {
-			return &user, nil
+		err := where(repo.db).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, cache.ErrNotFound
 		}
+		return user, err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &user, nil
+}
 
-	// 数据库
+func (repo *mysqlUserRepo) GetRole(user *model.User) (string, error) {
+	return user.Role, nil
+}
+
+func (repo *mysqlUserRepo) UpdatePassword(userID int, hashedPassword string) error {
 	var user model.User
-	err := repo.db.Where("email = ?", email).First(&user).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// 防止缓存穿透
-			if repo.cache != nil {
-				cacheKey := fmt.Sprintf("user:email:%s", email)
-				emptyUser := struct{}{}
-				err := repo.cache.Set(cacheKey, emptyUser, 1*time.Minute)
-				if err != nil {
-					return nil, errors.New("set cache failed")
-				}
-			}
-			return nil, errors.New("email select failed")
-		}
-		return nil, errors.New("email select failed")
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
 	}
 
-	//写入缓存
-	if repo.cache != nil {
-		// 分布式锁
-		lockKey := fmt.Sprintf("lock:user:email:%s", email)
-		if success, _ := repo.cache.Lock(lockKey, 10*time.Second); success {
-			defer repo.cache.Unlock(lockKey)
-
-			userCacheKey := fmt.Sprintf("user:id:%d", user.UserID)
-			err := repo.cache.Set(userCacheKey, &user, repo.cache.RandExp(5*time.Minute))
-			if err != nil {
-				return nil, errors.New("set cache failed")
-			}
-
-			usernameCacheKey := fmt.Sprintf("user:username:%s", user.Username)
-			err = repo.cache.Set(usernameCacheKey, &user, repo.cache.RandExp(5*time.Minute))
-			if err != nil {
-				return nil, errors.New("set cache failed")
-			}
-
-			emailCacheKey := fmt.Sprintf("user:email:%s", user.Email)
-			err = repo.cache.Set(emailCacheKey, &user, repo.cache.RandExp(5*time.Minute))
-			if err != nil {
-				return nil, errors.New("set cache failed")
-			}
-		}
+	if err := repo.db.Model(&model.User{}).
+		Where("user_id = ?", userID).
+		Update("password", hashedPassword).Error; err != nil {
+		return errors.New("password update failed")
 	}
-	return &user, nil
+
+	// 写后删除
+	return repo.cleanUserCache(&user)
 }
 
-func (repo *mysqlUserRepo) Exists(username, email string) bool {
-	// 缓存
-	if repo.cache != nil {
-		cacheKey := fmt.Sprintf("user:username:%s", username)
-		var user model.User
-		if err := repo.cache.Get(cacheKey, &user); err == nil {
-			return user.Email == email
-		}
+func (repo *mysqlUserRepo) MarkEmailVerified(userID int) error {
+	var user model.User
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := repo.db.Model(&model.User{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"email_verified":    true,
+			"email_verified_at": time.Now(),
+		}).Error; err != nil {
+		return errors.New("email verify failed")
+	}
+
+	// 写后删除
+	return repo.cleanUserCache(&user)
+}
+
+func (repo *mysqlUserRepo) UpdateRole(userID int, role string) error {
+	var user model.User
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := repo.db.Model(&model.User{}).
+		Where("user_id = ?", userID).
+		Update("role", role).Error; err != nil {
+		return errors.New("role update failed")
+	}
+
+	// 写后删除
+	return repo.cleanUserCache(&user)
+}
+
+func (repo *mysqlUserRepo) UpdateUsername(userID int, username string) error {
+	var user model.User
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	if user.Username == username {
+		return nil
 	}
 
-	//数据库
 	var count int64
-	repo.db.Where("username = ? AND email = ?", username, email).Count(&count)
-	return count > 0
+	repo.db.Unscoped().Model(&model.User{}).Where("username = ?", username).Count(&count)
+	if count > 0 {
+		return errors.New("user already exists")
+	}
+
+	if err := repo.db.Model(&model.User{}).
+		Where("user_id = ?", userID).
+		Update("username", username).Error; err != nil {
+		return errors.New("username update failed")
+	}
+
+	// 写后删除：旧用户名，以及新用户名可能存在的空值缓存
+	updated := user
+	updated.Username = username
+	return repo.cleanUserCache(&user, &updated)
 }
 
-func (repo *mysqlUserRepo) GetRole(user *model.User) (string, error) {
-	return user.Role, nil
+func (repo *mysqlUserRepo) UpdateEmail(userID int, email string) error {
+	var user model.User
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	var count int64
+	repo.db.Unscoped().Model(&model.User{}).Where("email = ? AND user_id <> ?", email, userID).Count(&count)
+	if count > 0 {
+		return errors.New("email already exists")
+	}
+
+	if err := repo.db.Model(&model.User{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"email":             email,
+			"email_verified":    true,
+			"email_verified_at": time.Now(),
+		}).Error; err != nil {
+		return errors.New("email update failed")
+	}
+
+	// 写后删除：旧邮箱，以及新邮箱可能存在的空值缓存
+	updated := user
+	updated.Email = email
+	return repo.cleanUserCache(&user, &updated)
+}
+
+func (repo *mysqlUserRepo) ListUsers(filter model.UserFilter) ([]model.User, error) {
+	query := repo.db.Model(&model.User{}).Where("user_id > ?", filter.Cursor)
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.EmailDomain != "" {
+		domain := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.EmailDomain)
+		query = query.Where("email LIKE ?", "%@"+domain)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore)
+	}
+
+	var users []model.User
+	if err := query.Order("user_id ASC").Limit(filter.Limit).Find(&users).Error; err != nil {
+		return nil, errors.New("user select failed")
+	}
+	return users, nil
+}
+
+func (repo *mysqlUserRepo) SetDisabled(userID int, disabled bool) error {
+	var user model.User
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	var disabledAt interface{}
+	if disabled {
+		disabledAt = time.Now()
+	}
+	if err := repo.db.Model(&model.User{}).
+		Where("user_id = ?", userID).
+		Update("disabled_at", disabledAt).Error; err != nil {
+		return errors.New("user update failed")
+	}
+
+	// 写后删除
+	return repo.cleanUserCache(&user)
+}
+
+func (repo *mysqlUserRepo) SoftDelete(userID int) error {
+	var user model.User
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := repo.db.Delete(&model.User{}, userID).Error; err != nil {
+		return errors.New("user delete failed")
+	}
+
+	// 写后删除
+	return repo.cleanUserCache(&user)
+}
+
+func (repo *mysqlUserRepo) Restore(userID int) error {
+	result := repo.db.Unscoped().Model(&model.User{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return errors.New("user restore failed")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	// 清除注销期间可能写入的空值缓存
+	var user model.User
+	if err := repo.db.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	return repo.cleanUserCache(&user)
+}
+
+func (repo *mysqlUserRepo) ListDeletedBefore(t time.Time) ([]int, error) {
+	var userIDs []int
+	if err := repo.db.Unscoped().Model(&model.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", t).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, errors.New("user select failed")
+	}
+	return userIDs, nil
+}
+
+func (repo *mysqlUserRepo) Purge(userID int) error {
+	if err := repo.db.Unscoped().Delete(&model.User{}, userID).Error; err != nil {
+		return errors.New("user purge failed")
+	}
+	return nil
+}
+
+// cleanUserCache 删除用户的全部缓存键
+func (repo *mysqlUserRepo) cleanUserCache(users ...*model.User) error {
+	if repo.cache == nil {
+		return nil
+	}
+
+	var keys []string
+	for _, user := range users {
+		keys = append(keys,
+			fmt.Sprintf("user:id:%d", user.UserID),
+			fmt.Sprintf("user:username:%s", user.Username),
+			fmt.Sprintf("user:email:%s", user.Email),
+		)
+	}
+	if err := repo.cache.Clean(keys...); err != nil {
+		return errors.New("cache clean failed")
+	}
+	return nil
 }
@@ -0,0 +1,21 @@
+package dao
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRevocationUnavailable 吊销存储不可用且配置为 fail-closed
+var ErrRevocationUnavailable = errors.New("revocation store unavailable")
+
+type RevocationRepository interface {
+	// RevokeToken 将 jti 加入黑名单直到令牌自然过期
+	RevokeToken(jti string, expiresAt time.Time) error
+	IsTokenRevoked(jti string) (bool, error)
+	// RevokeUserTokensBefore 该用户在 t 之前签发的令牌全部失效
+	RevokeUserTokensBefore(userID int, t time.Time) error
+	IsUserTokenRevoked(userID int, issuedAt time.Time) (bool, error)
+	// RevokeSessionTokens 该会话签发的访问令牌全部失效
+	RevokeSessionTokens(sessionID string) error
+	IsSessionRevoked(sessionID string) (bool, error)
+}
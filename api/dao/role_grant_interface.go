@@ -0,0 +1,9 @@
+package dao
+
+import "GoGin/internal/model"
+
+type RoleGrantRepository interface {
+	RecordGrant(grant *model.RoleGrant) error
+	// ListGrants userID 为 0 时返回全部记录
+	ListGrants(userID int) ([]model.RoleGrant, error)
+}
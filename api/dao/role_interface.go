@@ -0,0 +1,13 @@
+package dao
+
+import "GoGin/internal/model"
+
+type RoleRepository interface {
+	ListRoles() ([]model.Role, error)
+	GetRole(name string) (*model.Role, error)
+	CreateRole(role *model.Role, permissions []string) error
+	SetPermissions(roleName string, permissions []string) error
+	ListPermissions() ([]model.Permission, error)
+	// GetPermissions 角色的权限名列表，带缓存
+	GetPermissions(roleName string) ([]string, error)
+}
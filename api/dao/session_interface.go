@@ -0,0 +1,21 @@
+package dao
+
+import (
+	"GoGin/internal/model"
+	"time"
+)
+
+type SessionRepository interface {
+	CreateSession(session *model.Session) error
+	GetSession(sessionID string) (*model.Session, error)
+	// RotateSession 仅当当前令牌哈希等于 oldHash 时替换为 newHash
+	RotateSession(sessionID, oldHash, newHash string, expiresAt time.Time) error
+	RevokeSession(sessionID string) error
+	RevokeUserSessions(userID int) error
+	// DeleteUserSessions 彻底删除账号时清除该用户的全部会话
+	DeleteUserSessions(userID int) error
+	// ListUserSessions 未吊销且未过期的会话，最近活跃的在前
+	ListUserSessions(userID int) ([]model.Session, error)
+	// TouchSession 更新最近活跃时间和设备信息
+	TouchSession(sessionID string, client model.ClientInfo, seenAt time.Time) error
+}
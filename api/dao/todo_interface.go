@@ -7,4 +7,7 @@ type TodoRepository interface {
 	DeleteTodoTask(taskID int) error
 	FinishTodoTask(taskID int) error
 	CheckTodoTask(userID int) ([]model.TodoTask, []model.TodoTask, error)
+	ArchiveUserTodos(userID int) error
+	UnarchiveUserTodos(userID int) error
+	DeleteUserTodos(userID int) error
 }
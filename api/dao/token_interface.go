@@ -0,0 +1,10 @@
+package dao
+
+import "GoGin/internal/model"
+
+type TokenRepository interface {
+	CreateToken(token *model.OneTimeToken) error
+	// ConsumeToken 原子地标记令牌已使用，过期、已用或不存在均返回错误
+	ConsumeToken(purpose, tokenHash string) (*model.OneTimeToken, error)
+	DeleteUserTokens(userID int, purpose string) error
+}
@@ -0,0 +1,22 @@
+package dao
+
+import (
+	"GoGin/internal/model"
+	"errors"
+)
+
+// ErrTwoFactorNotFound 用户没有双因素认证配置
+var ErrTwoFactorNotFound = errors.New("two-factor not configured")
+
+type TwoFactorRepository interface {
+	// GetTwoFactor 没有配置时返回 ErrTwoFactorNotFound，其他错误表示存储故障
+	GetTwoFactor(userID int) (*model.TwoFactor, error)
+	// SaveTwoFactor 新建或覆盖未启用的配置
+	SaveTwoFactor(twoFactor *model.TwoFactor) error
+	EnableTwoFactor(userID int) error
+	DisableTwoFactor(userID int) error
+	// UseStep 仅当 step 大于上次使用的计数器时记录并返回 true
+	UseStep(userID int, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID int, codeHashes []string) error
+	UseRecoveryCode(userID int, codeHash string) error
+}
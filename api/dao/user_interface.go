@@ -2,12 +2,33 @@ package dao
 
 import (
 	"GoGin/internal/model"
+	"time"
 )
 
 type UserRepository interface {
 	AddUser(user *model.User) error
+	SelectByID(userID int) (*model.User, error)
 	SelectByUsername(username string) (*model.User, error)
 	SelectByEmail(email string) (*model.User, error)
+	// SelectCredentials 不经过缓存读取用户，包含密码哈希；缓存中的用户不含密码
+	SelectCredentials(userID int) (*model.User, error)
 	Exists(username, email string) bool
 	GetRole(user *model.User) (string, error)
+	UpdatePassword(userID int, hashedPassword string) error
+	MarkEmailVerified(userID int) error
+	UpdateRole(userID int, role string) error
+	UpdateUsername(userID int, username string) error
+	// UpdateEmail 修改邮箱并标记为已验证，调用前需完成新邮箱的验证
+	UpdateEmail(userID int, email string) error
+	SetDisabled(userID int, disabled bool) error
+	// ListUsers 按条件分页查询，返回的用户按 user_id 升序
+	ListUsers(filter model.UserFilter) ([]model.User, error)
+	// SoftDelete 注销账号，注销后的账号无法被查询到
+	SoftDelete(userID int) error
+	// Restore 恢复宽限期内已注销的账号
+	Restore(userID int) error
+	// ListDeletedBefore 注销时间早于 t 的账号
+	ListDeletedBefore(t time.Time) ([]int, error)
+	// Purge 彻底删除账号
+	Purge(userID int) error
 }
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/middleware"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// Delete 注销当前账号
+func (h *AccountHandler) Delete(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.accountService.DeleteAccount(userID, req); err != nil {
+		profileError(c, err)
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "account deleted, a restore link has been sent to your email")
+}
+
+// Restore 宽限期内恢复账号
+func (h *AccountHandler) Restore(c *gin.Context) {
+	//绑定数据
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.accountService.Restore(req.Token); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "account restored")
+}
+
+// Disable 管理员停用账号
+func (h *AccountHandler) Disable(c *gin.Context) {
+	//捕获数据
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid user id")
+		return
+	}
+
+	//调用服务层
+	if err := h.accountService.Disable(userID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "account disabled")
+}
+
+// Enable 管理员启用账号
+func (h *AccountHandler) Enable(c *gin.Context) {
+	//捕获数据
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid user id")
+		return
+	}
+
+	//调用服务层
+	if err := h.accountService.Enable(userID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "account enabled")
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminUserHandler struct {
+	userService *services.UserService
+}
+
+func NewAdminUserHandler(userService *services.UserService) *AdminUserHandler {
+	return &AdminUserHandler{
+		userService: userService,
+	}
+}
+
+// Unlock 解除账号的登录失败锁定
+func (h *AdminUserHandler) Unlock(c *gin.Context) {
+	//捕获数据
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid user id")
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.UnlockAccount(userID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "account unlocked")
+}
+
+// List 用户列表，支持按角色、邮箱域名和注册时间过滤
+func (h *AdminUserHandler) List(c *gin.Context) {
+	//绑定数据
+	var req model.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	users, nextCursor, err := h.userService.ListUsers(req)
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"users":       users,
+		"next_cursor": nextCursor,
+	}, "user list")
+}
+
+// Get 用户详情
+func (h *AdminUserHandler) Get(c *gin.Context) {
+	//捕获数据
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid user id")
+		return
+	}
+
+	//调用服务层
+	user, err := h.userService.GetUser(userID)
+	if err != nil {
+		util.Error(c, 404, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"user": user,
+	}, "user info")
+}
+
+// ForceLogout 强制下线
+func (h *AdminUserHandler) ForceLogout(c *gin.Context) {
+	//捕获数据
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid user id")
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.ForceLogout(userID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "user logged out")
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/middleware"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// Create 创建 API Key
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	rawKey, key, err := h.apiKeyService.CreateAPIKey(userID, req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"key":     rawKey,
+		"api_key": key,
+	}, "api key created, the key is shown only once")
+}
+
+// List 当前用户的 API Key
+func (h *APIKeyHandler) List(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	keys, err := h.apiKeyService.ListAPIKeys(userID)
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"api_keys": keys,
+	}, "api key list")
+}
+
+// Revoke 吊销 API Key
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid api key id")
+		return
+	}
+
+	//调用服务层
+	if err := h.apiKeyService.RevokeAPIKey(userID, keyID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "api key revoked")
+}
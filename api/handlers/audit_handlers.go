@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// List 按操作者、动作和时间范围查询审计日志
+func (h *AuditHandler) List(c *gin.Context) {
+	//绑定数据
+	var req model.ListAuditRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	entries, nextCursor, err := h.auditService.ListAudit(req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"entries":     entries,
+		"next_cursor": nextCursor,
+	}, "audit log")
+}
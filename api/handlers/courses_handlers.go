@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"GoGin/api/services"
+	"GoGin/internal/middleware"
 	"GoGin/internal/model"
 	"GoGin/internal/util"
 
@@ -34,10 +35,14 @@ func (h *CourseHandler) Info(c *gin.Context) {
 // EnrollmentInfo 获取已选课程列表 Get
 func (h *CourseHandler) EnrollmentInfo(c *gin.Context) {
 	//捕获数据
-	userID, _ := c.Get("user_id")
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
 
 	//调用服务层
-	courses, err := h.CourseService.GetEnrollmentInfo(userID.(int))
+	courses, err := h.CourseService.GetEnrollmentInfo(userID)
 	if err != nil {
 		util.Error(c, 500, err.Error())
 		return
@@ -55,13 +60,19 @@ func (h *CourseHandler) PickCourse(c *gin.Context) {
 	var req model.PickRequest
 	if err := c.ShouldBind(&req); err != nil {
 		util.Error(c, 400, err.Error())
+		return
+	}
+	studentID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
 	}
-	studentID, _ := c.Get("user_id")
 
 	//调用服务层
-	course, err := h.CourseService.PickCourse(studentID.(int), req.CourseID)
+	course, err := h.CourseService.PickCourse(studentID, req.CourseID)
 	if err != nil {
 		util.Error(c, 500, err.Error())
+		return
 	}
 
 	//返回响应
@@ -76,13 +87,19 @@ func (h *CourseHandler) DropCourse(c *gin.Context) {
 	var req model.DropRequest
 	if err := c.ShouldBind(&req); err != nil {
 		util.Error(c, 400, err.Error())
+		return
+	}
+	studentID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
 	}
-	studentID, _ := c.Get("user_id")
 
 	//调用服务层
-	course, err := h.CourseService.DropCourse(studentID.(int), req.CourseID)
+	course, err := h.CourseService.DropCourse(studentID, req.CourseID)
 	if err != nil {
 		util.Error(c, 500, err.Error())
+		return
 	}
 
 	//返回响应
@@ -97,12 +114,14 @@ func (h *CourseHandler) AddCourse(c *gin.Context) {
 	var req model.AddCourseRequest
 	if err := c.ShouldBind(&req); err != nil {
 		util.Error(c, 400, err.Error())
+		return
 	}
 
 	//调用服务层
 	course, err := h.CourseService.AddCourse(req.Name, req.Capital)
 	if err != nil {
 		util.Error(c, 500, err.Error())
+		return
 	}
 
 	//返回响应
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/middleware"
+	"GoGin/internal/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonationHandler struct {
+	impersonationService *services.ImpersonationService
+}
+
+func NewImpersonationHandler(impersonationService *services.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+	}
+}
+
+// Impersonate 以目标用户身份登录，用于排查用户反馈的问题
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	//捕获数据
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid user id")
+		return
+	}
+
+	//调用服务层
+	token, expiresAt, err := h.impersonationService.Impersonate(principal, userID)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"token":      token,
+		"expires_at": expiresAt,
+		"user_id":    userID,
+	}, "impersonation token issued")
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/middleware"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+}
+
+func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+	}
+}
+
+// CreateInvitation 签发邀请码
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	//捕获数据
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	code, invitation, err := h.invitationService.CreateInvitation(adminID, req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"code":       code,
+		"invitation": invitation,
+	}, "invitation created, the code is shown only once")
+}
+
+// ListInvitations 邀请码列表
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	//调用服务层
+	invitations, err := h.invitationService.ListInvitations()
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"invitations": invitations,
+	}, "invitation list")
+}
+
+// RevokeInvitation 吊销邀请码
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	//捕获数据
+	invitationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid invitation id")
+		return
+	}
+
+	//调用服务层
+	if err := h.invitationService.RevokeInvitation(invitationID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "invitation revoked")
+}
+
+// RequestRole 用户提交角色申请
+func (h *InvitationHandler) RequestRole(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.RoleRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	request, err := h.invitationService.RequestRole(userID, req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"request": request,
+	}, "role request submitted")
+}
+
+// MyRequests 当前用户的角色申请
+func (h *InvitationHandler) MyRequests(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	requests, err := h.invitationService.ListMyRequests(userID)
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"requests": requests,
+	}, "role request list")
+}
+
+// ListRequests 角色申请列表，可按 status 过滤
+func (h *InvitationHandler) ListRequests(c *gin.Context) {
+	//调用服务层
+	requests, err := h.invitationService.ListRequests(c.Query("status"))
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"requests": requests,
+	}, "role request list")
+}
+
+// Approve 批准角色申请
+func (h *InvitationHandler) Approve(c *gin.Context) {
+	//捕获数据
+	adminID, requestID, req, ok := bindDecision(c)
+	if !ok {
+		return
+	}
+
+	//调用服务层
+	user, err := h.invitationService.Approve(requestID, adminID, req.Note)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"user_id":  user.UserID,
+		"username": user.Username,
+		"role":     user.Role,
+	}, "role request approved")
+}
+
+// Reject 驳回角色申请
+func (h *InvitationHandler) Reject(c *gin.Context) {
+	//捕获数据
+	adminID, requestID, req, ok := bindDecision(c)
+	if !ok {
+		return
+	}
+
+	//调用服务层
+	if err := h.invitationService.Reject(requestID, adminID, req.Note); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "role request rejected")
+}
+
+func bindDecision(c *gin.Context) (int, int, model.DecideRoleRequestRequest, bool) {
+	var req model.DecideRoleRequestRequest
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return 0, 0, req, false
+	}
+	requestID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid request id")
+		return 0, 0, req, false
+	}
+	// 备注可选，允许空请求体
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			util.Error(c, 400, err.Error())
+			return 0, 0, req, false
+		}
+	}
+	return adminID, requestID, req, true
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"GoGin/internal/util/jwt_util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	jwtUtil jwt_util.Util
+}
+
+func NewJWKSHandler(jwtUtil jwt_util.Util) *JWKSHandler {
+	return &JWKSHandler{
+		jwtUtil: jwtUtil,
+	}
+}
+
+// JWKS 公开验签公钥，按 RFC 7517 直接返回而不包装响应结构
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, h.jwtUtil.JWKS())
+}
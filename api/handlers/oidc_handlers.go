@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/middleware"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+}
+
+func NewOIDCHandler(oidcService *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// Providers 已配置的身份提供方
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	util.Success(c, gin.H{
+		"providers": h.oidcService.Providers.Names(),
+	}, "identity providers")
+}
+
+// Login 返回授权地址，客户端跳转到该地址完成登录
+func (h *OIDCHandler) Login(c *gin.Context) {
+	//调用服务层
+	authURL, err := h.oidcService.AuthURL(c.Request.Context(), c.Param("provider"), 0)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"authorization_url": authURL,
+	}, "redirect to the identity provider")
+}
+
+// Link 已登录用户绑定外部身份，回调时完成绑定
+func (h *OIDCHandler) Link(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	authURL, err := h.oidcService.AuthURL(c.Request.Context(), c.Param("provider"), userID)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"authorization_url": authURL,
+	}, "redirect to the identity provider")
+}
+
+// Callback 身份提供方回调
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	//绑定数据
+	var req model.OIDCCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+	if req.Error != "" {
+		util.Error(c, 400, "identity provider error: "+req.Error)
+		return
+	}
+	if req.Code == "" || req.State == "" {
+		util.Error(c, 400, "missing code or state")
+		return
+	}
+
+	//调用服务层
+	result, err := h.oidcService.Callback(c.Request.Context(), c.Param("provider"), req.Code, req.State, middleware.ClientInfo(c))
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+
+	//返回响应
+	if result.Linked {
+		middleware.SetAuditActor(c, result.Identity.UserID, "")
+		middleware.SetAuditDetail(c, "identity linked")
+		util.Success(c, gin.H{
+			"identity": result.Identity,
+		}, "identity linked")
+		return
+	}
+	middleware.SetAuditActor(c, result.Login.User.UserID, result.Login.User.Username)
+	if result.Login.MFARequired {
+		middleware.SetAuditDetail(c, "mfa_required")
+		util.Success(c, gin.H{
+			"mfa_required":    true,
+			"challenge_token": result.Login.ChallengeToken,
+		}, "second factor required")
+		return
+	}
+	loginResponse(c, result.Login)
+}
+
+// ListIdentities 当前用户已绑定的外部身份
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	identities, err := h.oidcService.ListIdentities(userID)
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"identities": identities,
+	}, "identity list")
+}
+
+// Unlink 解绑外部身份
+func (h *OIDCHandler) Unlink(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	identityID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid identity id")
+		return
+	}
+
+	//调用服务层
+	if err := h.oidcService.Unlink(userID, identityID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "identity unlinked")
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/middleware"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// ListRoles 角色及其权限列表
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	//调用服务层
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"roles": roles,
+	}, "role list")
+}
+
+// ListPermissions 全部权限
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	//调用服务层
+	permissions, err := h.roleService.ListPermissions()
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"permissions": permissions,
+	}, "permission list")
+}
+
+// CreateRole 新增角色
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	//捕获数据
+	var req model.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	role, err := h.roleService.CreateRole(req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"role": role,
+	}, "role created")
+}
+
+// SetPermissions 覆盖角色的权限
+func (h *RoleHandler) SetPermissions(c *gin.Context) {
+	//捕获数据
+	var req model.SetPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	role, err := h.roleService.SetRolePermissions(c.Param("name"), req.Permissions)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"role": role,
+	}, "role permissions updated")
+}
+
+// AssignRole 修改用户角色
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	//捕获数据
+	adminID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		util.Error(c, 400, "invalid user id")
+		return
+	}
+	var req model.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	user, err := h.roleService.AssignRole(adminID, userID, req.Role)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"user_id":  user.UserID,
+		"username": user.Username,
+		"role":     user.Role,
+	}, "role assigned")
+}
+
+// ListGrants 角色授予记录，可按 user_id 过滤
+func (h *RoleHandler) ListGrants(c *gin.Context) {
+	//捕获数据
+	userID, _ := strconv.Atoi(c.Query("user_id"))
+
+	//调用服务层
+	grants, err := h.roleService.ListGrants(userID)
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"grants": grants,
+	}, "role grant list")
+}
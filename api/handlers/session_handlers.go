@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"GoGin/internal/middleware"
+	"GoGin/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessions 当前用户已登录的设备
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	//捕获数据
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	sessions, err := h.userService.ListSessions(principal.UserID, principal.SessionID)
+	if err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"sessions": sessions,
+	}, "")
+}
+
+// RevokeSession 下线指定设备
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.RevokeUserSession(userID, c.Param("id")); err != nil {
+		util.Error(c, 404, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "session revoked")
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"GoGin/api/services"
+	"GoGin/internal/middleware"
 	"GoGin/internal/model"
 	"GoGin/internal/util"
 
@@ -19,14 +20,19 @@ func NewTodoHandler(todoService *services.TodoService) *TodoHandler {
 // Create 新增事项
 func (h *TodoHandler) Create(c *gin.Context) {
 	//捕获数据
-	userID, _ := c.Get("user_id")
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
 	var req model.CreateTodoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		util.Error(c, 400, err.Error())
+		return
 	}
 
 	// 调用服务层
-	todoTask, err := h.TodoService.CreateTodoTask(req, userID.(int))
+	todoTask, err := h.TodoService.CreateTodoTask(req, userID)
 	if err != nil {
 		util.Error(c, 500, err.Error())
 		return
@@ -44,6 +50,7 @@ func (h *TodoHandler) Finish(c *gin.Context) {
 	var req model.FinishTodoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		util.Error(c, 400, err.Error())
+		return
 	}
 
 	//调用服务层
@@ -66,6 +73,7 @@ func (h *TodoHandler) Delete(c *gin.Context) {
 	var req model.DeleteTodoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		util.Error(c, 400, err.Error())
+		return
 	}
 
 	//调用服务层
@@ -84,10 +92,14 @@ func (h *TodoHandler) Delete(c *gin.Context) {
 // Info 获取事项列表
 func (h *TodoHandler) Info(c *gin.Context) {
 	//捕获数据
-	userID, _ := c.Get("user_id")
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
 
 	//调用服务层
-	todos, dones, err := h.TodoService.GetInfo(userID.(int))
+	todos, dones, err := h.TodoService.GetInfo(userID)
 	if err != nil {
 		util.Error(c, 500, err.Error())
 		return
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"GoGin/api/services"
+	"GoGin/internal/middleware"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// Setup 生成 TOTP 密钥和 otpauth:// 链接
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	secret, uri, err := h.twoFactorService.Setup(userID)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"secret":      secret,
+		"otpauth_uri": uri,
+	}, "scan the uri with your authenticator and confirm with a code")
+}
+
+// Confirm 确认绑定，返回恢复码
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	codes, err := h.twoFactorService.Confirm(userID, req.Code)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"recovery_codes": codes,
+	}, "two-factor enabled, store the recovery codes safely")
+}
+
+// Disable 关闭双因素认证
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.twoFactorService.Disable(userID, req.Code, middleware.ClientInfo(c)); err != nil {
+		twoFactorError(c, 400, err)
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "two-factor disabled")
+}
+
+// RecoveryCodes 重新生成恢复码
+func (h *TwoFactorHandler) RecoveryCodes(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(userID, req.Code, middleware.ClientInfo(c))
+	if err != nil {
+		twoFactorError(c, 400, err)
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"recovery_codes": codes,
+	}, "recovery codes regenerated")
+}
+
+// StepUp 换取带 amr=otp 的访问令牌
+func (h *TwoFactorHandler) StepUp(c *gin.Context) {
+	//捕获数据
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	token, err := h.twoFactorService.StepUp(principal.UserID, principal.SessionID, req.Code, middleware.ClientInfo(c))
+	if err != nil {
+		twoFactorError(c, 401, err)
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"token": token,
+	}, "step-up successful")
+}
+
+// twoFactorError 验证码失败次数过多时与登录一致返回 429，其余按 status 返回
+func twoFactorError(c *gin.Context, status int, err error) {
+	var throttled *services.ThrottledError
+	if errors.As(err, &throttled) {
+		loginError(c, err)
+		return
+	}
+	util.Error(c, status, err.Error())
+}
@@ -2,8 +2,14 @@ package handlers
 
 import (
 	"GoGin/api/services"
+	"GoGin/internal/middleware"
 	"GoGin/internal/model"
 	"GoGin/internal/util"
+	"GoGin/internal/util/password_policy"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,11 +33,17 @@ func (h *UserHandler) Register(c *gin.Context) {
 	}
 
 	//调用服务层
+	middleware.SetAuditTarget(c, req.Username)
 	user, err := h.userService.Register(&req)
 	if err != nil {
+		if passwordPolicyError(c, err) {
+			return
+		}
 		util.Error(c, 500, err.Error())
 		return
 	}
+	middleware.SetAuditActor(c, user.UserID, user.Username)
+	middleware.SetAuditDetail(c, "role="+user.Role)
 
 	//返回响应
 	util.Success(c, gin.H{
@@ -50,32 +62,97 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	//调用服务层
-	token, user, err, refreshToken := h.userService.Login(req.LoginKey, req.Password)
+	middleware.SetAuditTarget(c, req.LoginKey)
+	result, err := h.userService.Login(req.LoginKey, req.Password, middleware.ClientInfo(c))
 	if err != nil {
-		util.Error(c, 500, err.Error())
+		loginError(c, err)
+		return
+	}
+	middleware.SetAuditActor(c, result.User.UserID, result.User.Username)
+
+	//需要第二步验证
+	if result.MFARequired {
+		middleware.SetAuditDetail(c, "mfa_required")
+		util.Success(c, gin.H{
+			"mfa_required":    true,
+			"challenge_token": result.ChallengeToken,
+		}, "second factor required")
+		return
 	}
 
 	//返回响应
+	loginResponse(c, result)
+}
+
+func (h *UserHandler) LoginSecondFactor(c *gin.Context) {
+	//捕获数据
+	var req model.LoginSecondFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	result, err := h.userService.LoginSecondFactor(req, middleware.ClientInfo(c))
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+	middleware.SetAuditActor(c, result.User.UserID, result.User.Username)
+
+	//返回响应
+	loginResponse(c, result)
+}
+
+// loginError 限流返回 429 和 Retry-After，其余登录失败统一返回 401
+func loginError(c *gin.Context, err error) {
+	var throttled *services.ThrottledError
+	if errors.As(err, &throttled) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		util.Error(c, 429, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrAccountDisabled) {
+		util.Error(c, 403, err.Error())
+		return
+	}
+	util.Error(c, 401, err.Error())
+}
+
+func loginResponse(c *gin.Context, result *services.LoginResult) {
 	util.Success(c, gin.H{
-		"username":      user.Username,
-		"user_id":       user.UserID,
-		"email":         user.Email,
-		"token":         token,
-		"refresh_token": refreshToken,
+		"username":      result.User.Username,
+		"user_id":       result.User.UserID,
+		"email":         result.User.Email,
+		"token":         result.Token,
+		"refresh_token": result.RefreshToken,
 	}, "login successful")
 }
 
 func (h *UserHandler) InfoHandler(c *gin.Context) {
 	//捕获数据
-	userID, _ := c.Get("user_id")
-	username, _ := c.Get("username")
-	role, _ := c.Get("role")
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	info := gin.H{
+		"user_id":  principal.UserID,
+		"username": principal.Username,
+		"role":     principal.Role,
+	}
+	//模拟登录时标明实际操作的管理员
+	if principal.IsImpersonation() {
+		info["impersonated"] = true
+		info["impersonator"] = gin.H{
+			"user_id":  principal.ImpersonatorID,
+			"username": principal.ImpersonatorName,
+		}
+		info["expires_at"] = principal.ExpiresAt
+	}
+
 	//返回响应
-	util.Success(c, gin.H{
-		"user_id":  userID,
-		"username": username,
-		"role":     role,
-	}, "Your information")
+	util.Success(c, info, "Your information")
 }
 
 func (h *UserHandler) Refresh(c *gin.Context) {
@@ -87,14 +164,233 @@ func (h *UserHandler) Refresh(c *gin.Context) {
 	}
 
 	//调用服务层
-	token, err := h.userService.Refresh(req)
+	if sessionID, _, ok := strings.Cut(req.RefreshToken, "."); ok {
+		middleware.SetAuditTarget(c, "session:"+sessionID)
+	}
+	token, refreshToken, err := h.userService.Refresh(req, middleware.ClientInfo(c))
 	if err != nil {
-		util.Error(c, 500, err.Error())
+		util.Error(c, 401, err.Error())
 		return
 	}
 
 	//返回响应
 	util.Success(c, gin.H{
-		"new_token": token,
+		"new_token":     token,
+		"refresh_token": refreshToken,
 	}, "RefreshToken successfully")
 }
+
+func (h *UserHandler) Logout(c *gin.Context) {
+	//绑定数据
+	var req model.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.Logout(req, principal.TokenID, principal.ExpiresAt); err != nil {
+		util.Error(c, 401, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "logout successfully")
+}
+
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	//绑定数据
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.ForgotPassword(req.Email, middleware.ClientInfo(c)); err != nil {
+		util.Error(c, 500, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "if the email is registered, a reset link has been sent")
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	//绑定数据
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.ResetPassword(req); err != nil {
+		if passwordPolicyError(c, err) {
+			return
+		}
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "password reset successfully")
+}
+
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	//绑定数据
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.VerifyEmail(req.Token); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "email verified successfully")
+}
+
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.ResendVerification(userID); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "verification email sent")
+}
+
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.ChangePassword(userID, req); err != nil {
+		profileError(c, err)
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "password changed, please log in again")
+}
+
+func (h *UserHandler) ChangeUsername(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.ChangeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	user, err := h.userService.ChangeUsername(userID, req)
+	if err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, gin.H{
+		"user_id":  user.UserID,
+		"username": user.Username,
+	}, "username changed")
+}
+
+func (h *UserHandler) ChangeEmail(c *gin.Context) {
+	//捕获数据
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		util.Error(c, 401, "未登录！")
+		return
+	}
+	var req model.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.RequestEmailChange(userID, req); err != nil {
+		profileError(c, err)
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "a confirmation link has been sent to the new email")
+}
+
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	//绑定数据
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//调用服务层
+	if err := h.userService.ConfirmEmailChange(req.Token); err != nil {
+		util.Error(c, 400, err.Error())
+		return
+	}
+
+	//返回响应
+	util.Success(c, nil, "email changed successfully")
+}
+
+// profileError 重新认证失败按登录失败处理，其余为请求错误
+func profileError(c *gin.Context, err error) {
+	var throttled *services.ThrottledError
+	if errors.Is(err, services.ErrInvalidCredentials) || errors.As(err, &throttled) {
+		loginError(c, err)
+		return
+	}
+	if passwordPolicyError(c, err) {
+		return
+	}
+	util.Error(c, 400, err.Error())
+}
+
+// passwordPolicyError 密码未通过策略时返回 400 和逐条违规项
+func passwordPolicyError(c *gin.Context, err error) bool {
+	var policyErr *password_policy.PolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	util.ErrorWithData(c, 400, err.Error(), gin.H{
+		"violations": policyErr.Violations,
+	})
+	return true
+}
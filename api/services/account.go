@@ -0,0 +1,187 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/config"
+	"GoGin/internal/mailer"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrAccountDisabled 账号已被管理员停用
+var ErrAccountDisabled = errors.New("account disabled")
+
+// AccountService 账号停用、注销、恢复与彻底删除
+type AccountService struct {
+	UserRepo      dao.UserRepository
+	CourseRepo    dao.CourseRepository
+	TodoRepo      dao.TodoRepository
+	TokenRepo     dao.TokenRepository
+	SessionRepo   dao.SessionRepository
+	APIKeyRepo    dao.APIKeyRepository
+	TwoFactorRepo dao.TwoFactorRepository
+	IdentityRepo  dao.IdentityRepository
+	userService   *UserService
+	mailer        mailer.Mailer
+	cfg           *config.Config
+	gracePeriod   time.Duration
+}
+
+func NewAccountService(userRepo dao.UserRepository, courseRepo dao.CourseRepository, todoRepo dao.TodoRepository, tokenRepo dao.TokenRepository, sessionRepo dao.SessionRepository, apiKeyRepo dao.APIKeyRepository, twoFactorRepo dao.TwoFactorRepository, identityRepo dao.IdentityRepository, userService *UserService, mailer mailer.Mailer, cfg *config.Config) *AccountService {
+	return &AccountService{
+		UserRepo:      userRepo,
+		CourseRepo:    courseRepo,
+		TodoRepo:      todoRepo,
+		TokenRepo:     tokenRepo,
+		SessionRepo:   sessionRepo,
+		APIKeyRepo:    apiKeyRepo,
+		TwoFactorRepo: twoFactorRepo,
+		IdentityRepo:  identityRepo,
+		userService:   userService,
+		mailer:        mailer,
+		cfg:           cfg,
+		gracePeriod:   time.Duration(cfg.AccountDeletionGraceDays) * 24 * time.Hour,
+	}
+}
+
+// Disable 管理员停用账号，立即吊销全部令牌
+func (s *AccountService) Disable(userID int) error {
+	if err := s.UserRepo.SetDisabled(userID, true); err != nil {
+		return err
+	}
+	return s.userService.InvalidateUserTokens(userID)
+}
+
+func (s *AccountService) Enable(userID int) error {
+	return s.UserRepo.SetDisabled(userID, false)
+}
+
+// DeleteAccount 用户注销：退掉全部课程、归档待办事项，宽限期内可通过邮件链接恢复
+func (s *AccountService) DeleteAccount(userID int, req model.DeleteAccountRequest) error {
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.userService.reauthenticate(user, req.Password); err != nil {
+		return err
+	}
+
+	if err := s.CourseRepo.DropAllEnrollments(userID); err != nil {
+		return err
+	}
+	if err := s.TodoRepo.ArchiveUserTodos(userID); err != nil {
+		return err
+	}
+	if err := s.UserRepo.SoftDelete(userID); err != nil {
+		return err
+	}
+	if err := s.userService.InvalidateUserTokens(userID); err != nil {
+		return err
+	}
+
+	//恢复链接，发送失败不影响注销
+	if err := s.sendRestoreEmail(user); err != nil {
+		log.Println("send restore mail failed:", err)
+	}
+	return nil
+}
+
+// Restore 宽限期内使用恢复链接找回账号，已退的课程不会恢复
+func (s *AccountService) Restore(rawToken string) error {
+	token, err := s.TokenRepo.ConsumeToken(model.TokenPurposeRestore, util.HashToken(rawToken))
+	if err != nil {
+		return err
+	}
+	if err := s.UserRepo.Restore(token.UserID); err != nil {
+		return err
+	}
+	return s.TodoRepo.UnarchiveUserTodos(token.UserID)
+}
+
+// PurgeExpired 彻底删除超过宽限期的账号
+func (s *AccountService) PurgeExpired() (int, error) {
+	userIDs, err := s.UserRepo.ListDeletedBefore(time.Now().Add(-s.gracePeriod))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, userID := range userIDs {
+		if err := s.purge(userID); err != nil {
+			log.Printf("purge user %d failed: %v", userID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// RunPurger 按间隔执行清理，在独立的 goroutine 中运行
+func (s *AccountService) RunPurger(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if n, err := s.PurgeExpired(); err != nil {
+			log.Println("account purge failed:", err)
+		} else if n > 0 {
+			log.Printf("purged %d deleted accounts", n)
+		}
+	}
+}
+
+func (s *AccountService) purge(userID int) error {
+	// 注销时已退课，这里再执行一次以防注销过程中途失败
+	if err := s.CourseRepo.DropAllEnrollments(userID); err != nil {
+		return err
+	}
+	if err := s.TodoRepo.DeleteUserTodos(userID); err != nil {
+		return err
+	}
+	for _, purpose := range []string{model.TokenPurposePasswordReset, model.TokenPurposeVerifyEmail, model.TokenPurposeChangeEmail, model.TokenPurposeRestore} {
+		if err := s.TokenRepo.DeleteUserTokens(userID, purpose); err != nil {
+			return err
+		}
+	}
+	if err := s.SessionRepo.DeleteUserSessions(userID); err != nil {
+		return err
+	}
+	if err := s.APIKeyRepo.DeleteUserAPIKeys(userID); err != nil {
+		return err
+	}
+	if err := s.TwoFactorRepo.DisableTwoFactor(userID); err != nil {
+		return err
+	}
+	// 外部身份指向已删除的账号后，该身份将永远无法登录
+	if err := s.IdentityRepo.DeleteUserIdentities(userID); err != nil {
+		return err
+	}
+	return s.UserRepo.Purge(userID)
+}
+
+func (s *AccountService) sendRestoreEmail(user *model.User) error {
+	rawToken, err := util.RandomToken(32)
+	if err != nil {
+		return errors.New("token generate failed")
+	}
+	token := &model.OneTimeToken{
+		UserID:    user.UserID,
+		Purpose:   model.TokenPurposeRestore,
+		TokenHash: util.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(s.gracePeriod),
+	}
+	if err := s.TokenRepo.CreateToken(token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/user/account/restore?token=%s", s.cfg.AppBaseURL, rawToken)
+	body := fmt.Sprintf("Hi %s,\n\nYour account has been deleted and will be permanently removed in %d days. To restore it, open the link below before then.\n\n%s",
+		user.Username, s.cfg.AccountDeletionGraceDays, link)
+	return s.mailer.Send(user.Email, "Your account has been deleted", body)
+}
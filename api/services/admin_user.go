@@ -0,0 +1,54 @@
+package services
+
+import (
+	"GoGin/internal/model"
+	"strings"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+// ListUsers 管理员分页查询用户，nextCursor 为 0 表示没有下一页
+func (s *UserService) ListUsers(req model.ListUsersRequest) ([]model.User, int, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultUserPageSize
+	}
+	if limit > maxUserPageSize {
+		limit = maxUserPageSize
+	}
+
+	// 多取一条用于判断是否还有下一页
+	users, err := s.UserRepo.ListUsers(model.UserFilter{
+		Role:          req.Role,
+		EmailDomain:   strings.TrimPrefix(strings.TrimSpace(req.EmailDomain), "@"),
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		Cursor:        req.Cursor,
+		Limit:         limit + 1,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	nextCursor := 0
+	if len(users) > limit {
+		users = users[:limit]
+		nextCursor = users[limit-1].UserID
+	}
+	return users, nextCursor, nil
+}
+
+func (s *UserService) GetUser(userID int) (*model.User, error) {
+	return s.UserRepo.SelectByID(userID)
+}
+
+// ForceLogout 吊销用户的全部会话和访问令牌
+func (s *UserService) ForceLogout(userID int) error {
+	if _, err := s.UserRepo.SelectByID(userID); err != nil {
+		return err
+	}
+	return s.InvalidateUserTokens(userID)
+}
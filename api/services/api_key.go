@@ -0,0 +1,149 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+const maxAPIKeysPerUser = 20
+
+type APIKeyService struct {
+	APIKeyRepo dao.APIKeyRepository
+	UserRepo   dao.UserRepository
+	RoleRepo   dao.RoleRepository
+}
+
+func NewAPIKeyService(apiKeyRepo dao.APIKeyRepository, userRepo dao.UserRepository, roleRepo dao.RoleRepository) *APIKeyService {
+	return &APIKeyService{
+		APIKeyRepo: apiKeyRepo,
+		UserRepo:   userRepo,
+		RoleRepo:   roleRepo,
+	}
+}
+
+// CreateAPIKey 创建 API Key，明文只在创建时返回一次
+func (s *APIKeyService) CreateAPIKey(userID int, req model.CreateAPIKeyRequest) (string, *model.APIKey, error) {
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	scopes, err := s.checkScopes(user.Role, req.Scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys, err := s.APIKeyRepo.ListUserAPIKeys(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	active := 0
+	for _, key := range keys {
+		if key.RevokedAt == nil && time.Now().Before(key.ExpiresAt) {
+			active++
+		}
+	}
+	if active >= maxAPIKeysPerUser {
+		return "", nil, errors.New("too many api keys")
+	}
+
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return "", nil, errors.New("api key generate failed")
+	}
+	rawKey := model.APIKeyPrefix + secret
+	key := &model.APIKey{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Hint:      rawKey[:len(model.APIKeyPrefix)+4],
+		KeyHash:   util.HashToken(rawKey),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour),
+	}
+	if err := s.APIKeyRepo.CreateAPIKey(key); err != nil {
+		return "", nil, err
+	}
+	return rawKey, key, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(userID int) ([]model.APIKey, error) {
+	return s.APIKeyRepo.ListUserAPIKeys(userID)
+}
+
+func (s *APIKeyService) RevokeAPIKey(userID, keyID int) error {
+	return s.APIKeyRepo.RevokeAPIKey(userID, keyID)
+}
+
+// AuthenticateAPIKey 校验 API Key 并生成认证主体，实现 middleware.APIKeyAuthenticator
+func (s *APIKeyService) AuthenticateAPIKey(rawKey string) (*model.Principal, error) {
+	key, err := s.APIKeyRepo.GetAPIKeyByHash(util.HashToken(rawKey))
+	if err != nil {
+		return nil, errors.New("api key invalid")
+	}
+	if key.RevokedAt != nil {
+		return nil, errors.New("api key revoked")
+	}
+	if time.Now().After(key.ExpiresAt) {
+		return nil, errors.New("api key expired")
+	}
+
+	// 账号停用或注销后 Key 随之失效
+	user, err := s.UserRepo.SelectByID(key.UserID)
+	if err != nil || user.UserID == 0 {
+		return nil, errors.New("api key invalid")
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+
+	if err := s.APIKeyRepo.TouchAPIKey(key.ID, time.Now()); err != nil {
+		log.Println("api key touch failed:", err)
+	}
+
+	return &model.Principal{
+		UserID:        user.UserID,
+		Username:      user.Username,
+		Role:          user.Role,
+		Scopes:        key.Scopes,
+		ExpiresAt:     key.ExpiresAt,
+		EmailVerified: user.EmailVerified,
+		APIKeyID:      key.ID,
+	}, nil
+}
+
+// checkScopes 只允许路由组范围和当前角色已拥有的权限
+func (s *APIKeyService) checkScopes(role string, requested []string) ([]string, error) {
+	permissions, err := s.RoleRepo.GetPermissions(role)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool)
+	for _, scope := range model.APIKeyGroupScopes {
+		allowed[scope] = true
+	}
+	for _, permission := range permissions {
+		allowed[permission] = true
+	}
+
+	seen := make(map[string]bool)
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if seen[scope] {
+			continue
+		}
+		if !allowed[scope] {
+			return nil, errors.New("scope not allowed: " + scope)
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	return scopes, nil
+}
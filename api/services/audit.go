@@ -0,0 +1,57 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 100
+)
+
+type AuditService struct {
+	AuditRepo dao.AuditRepository
+}
+
+func NewAuditService(auditRepo dao.AuditRepository) *AuditService {
+	return &AuditService{
+		AuditRepo: auditRepo,
+	}
+}
+
+// ListAudit 按时间倒序分页查询审计日志，nextCursor 为 0 表示没有下一页
+func (s *AuditService) ListAudit(req model.ListAuditRequest) ([]model.AuditEntry, int, error) {
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return nil, 0, errors.New("from must be before to")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultAuditPageSize
+	}
+	if limit > maxAuditPageSize {
+		limit = maxAuditPageSize
+	}
+
+	// 多取一条用于判断是否还有下一页
+	entries, err := s.AuditRepo.ListAudit(model.AuditFilter{
+		ActorID: req.ActorID,
+		Action:  req.Action,
+		From:    req.From,
+		To:      req.To,
+		Cursor:  req.Cursor,
+		Limit:   limit + 1,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	nextCursor := 0
+	if len(entries) > limit {
+		entries = entries[:limit]
+		nextCursor = entries[limit-1].ID
+	}
+	return entries, nextCursor, nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// VerifyEmail 使用一次性令牌完成邮箱验证
+func (s *UserService) VerifyEmail(rawToken string) error {
+	token, err := s.TokenRepo.ConsumeToken(model.TokenPurposeVerifyEmail, util.HashToken(rawToken))
+	if err != nil {
+		return err
+	}
+
+	return s.UserRepo.MarkEmailVerified(token.UserID)
+}
+
+// ResendVerification 重新发送验证邮件，旧链接作废
+func (s *UserService) ResendVerification(userID int) error {
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return errors.New("email already verified")
+	}
+
+	return s.sendVerificationEmail(user)
+}
+
+func (s *UserService) sendVerificationEmail(user *model.User) error {
+	if err := s.TokenRepo.DeleteUserTokens(user.UserID, model.TokenPurposeVerifyEmail); err != nil {
+		return err
+	}
+
+	rawToken, err := util.RandomToken(32)
+	if err != nil {
+		return errors.New("token generate failed")
+	}
+	token := &model.OneTimeToken{
+		UserID:    user.UserID,
+		Purpose:   model.TokenPurposeVerifyEmail,
+		TokenHash: util.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(time.Duration(s.cfg.Verification.TTLHours) * time.Hour),
+	}
+	if err := s.TokenRepo.CreateToken(token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/user/verify?token=%s", s.cfg.AppBaseURL, rawToken)
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %d hours.\n\n%s",
+		user.Username, s.cfg.Verification.TTLHours, link)
+	return s.mailer.Send(user.Email, "Verify your email", body)
+}
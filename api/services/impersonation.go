@@ -0,0 +1,70 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/config"
+	"GoGin/internal/model"
+	"GoGin/internal/util/jwt_util"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type ImpersonationService struct {
+	UserRepo    dao.UserRepository
+	roleService *RoleService
+	jwtUtil     jwt_util.Util
+	ttl         time.Duration
+}
+
+func NewImpersonationService(userRepo dao.UserRepository, roleService *RoleService, jwtUtil jwt_util.Util, cfg *config.Config) *ImpersonationService {
+	return &ImpersonationService{
+		UserRepo:    userRepo,
+		roleService: roleService,
+		jwtUtil:     jwtUtil,
+		ttl:         time.Duration(cfg.ImpersonationTTLMinutes) * time.Minute,
+	}
+}
+
+// Impersonate 签发以目标用户身份访问的短期令牌，act 声明记录发起的管理员
+// 令牌没有刷新会话，不能模拟其他管理员，也不能在模拟期间再次模拟
+func (s *ImpersonationService) Impersonate(admin *model.Principal, targetUserID int) (string, time.Time, error) {
+	if admin.IsImpersonation() {
+		return "", time.Time{}, errors.New("cannot impersonate while impersonating")
+	}
+	if admin.UserID == targetUserID {
+		return "", time.Time{}, errors.New("cannot impersonate yourself")
+	}
+
+	user, err := s.UserRepo.SelectByID(targetUserID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if user.DisabledAt != nil {
+		return "", time.Time{}, ErrAccountDisabled
+	}
+	role, err := s.UserRepo.GetRole(user)
+	if err != nil {
+		return "", time.Time{}, errors.New("get role error")
+	}
+	if isAdmin, err := s.roleService.HasPermission(role, model.PermAdminAccess); err != nil || isAdmin {
+		return "", time.Time{}, errors.New("cannot impersonate an administrator")
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	claims := newAccessClaims(user, nil)
+	claims.Role = role
+	claims.Actor = &jwt_util.Actor{
+		Subject:  strconv.Itoa(admin.UserID),
+		UserID:   admin.UserID,
+		Username: admin.Username,
+	}
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	token, err := s.jwtUtil.GenerateToken(claims)
+	if err != nil {
+		return "", time.Time{}, errors.New("token generate failed")
+	}
+	return token, expiresAt, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"errors"
+	"time"
+)
+
+type InvitationService struct {
+	InvitationRepo dao.InvitationRepository
+	RoleRepo       dao.RoleRepository
+	roleService    *RoleService
+}
+
+func NewInvitationService(invitationRepo dao.InvitationRepository, roleRepo dao.RoleRepository, roleService *RoleService) *InvitationService {
+	return &InvitationService{
+		InvitationRepo: invitationRepo,
+		RoleRepo:       roleRepo,
+		roleService:    roleService,
+	}
+}
+
+// CreateInvitation 签发邀请码，明文只在创建时返回一次
+func (s *InvitationService) CreateInvitation(adminID int, req model.CreateInvitationRequest) (string, *model.Invitation, error) {
+	if _, err := s.RoleRepo.GetRole(req.Role); err != nil {
+		return "", nil, err
+	}
+
+	code, err := util.RandomToken(24)
+	if err != nil {
+		return "", nil, errors.New("invitation generate failed")
+	}
+	invitation := &model.Invitation{
+		CodeHash:  util.HashToken(code),
+		Role:      req.Role,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour),
+		CreatedBy: adminID,
+	}
+	if err := s.InvitationRepo.CreateInvitation(invitation); err != nil {
+		return "", nil, err
+	}
+	return code, invitation, nil
+}
+
+// BootstrapAdminInvitation 系统中还没有管理员时签发一次性的管理员邀请码，用它注册第一个管理员；
+// 已有管理员时返回空字符串。每次启动都会重新签发，旧的引导邀请码到期后失效
+func (s *InvitationService) BootstrapAdminInvitation(ttl time.Duration) (string, error) {
+	admins, err := s.roleService.UserRepo.ListUsers(model.UserFilter{Role: model.RoleAdmin, Limit: 1})
+	if err != nil {
+		return "", err
+	}
+	if len(admins) > 0 {
+		return "", nil
+	}
+
+	code, _, err := s.CreateInvitation(0, model.CreateInvitationRequest{
+		Role:           model.RoleAdmin,
+		MaxUses:        1,
+		ExpiresInHours: int(ttl.Hours()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+func (s *InvitationService) ListInvitations() ([]model.Invitation, error) {
+	return s.InvitationRepo.ListInvitations()
+}
+
+func (s *InvitationService) RevokeInvitation(invitationID int) error {
+	return s.InvitationRepo.RevokeInvitation(invitationID)
+}
+
+// RequestRole 用户申请特权角色，同一时间只能有一个待审批的申请
+func (s *InvitationService) RequestRole(userID int, req model.RoleRequestRequest) (*model.RoleRequest, error) {
+	if req.Role == model.RoleUser {
+		return nil, errors.New("role does not require approval")
+	}
+	if _, err := s.RoleRepo.GetRole(req.Role); err != nil {
+		return nil, err
+	}
+	pending, err := s.InvitationRepo.HasPendingRoleRequest(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, errors.New("role request already pending")
+	}
+
+	request := &model.RoleRequest{
+		UserID: userID,
+		Role:   req.Role,
+		Reason: req.Reason,
+		Status: model.RoleRequestPending,
+	}
+	if err := s.InvitationRepo.CreateRoleRequest(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (s *InvitationService) ListMyRequests(userID int) ([]model.RoleRequest, error) {
+	return s.InvitationRepo.ListUserRoleRequests(userID)
+}
+
+func (s *InvitationService) ListRequests(status string) ([]model.RoleRequest, error) {
+	return s.InvitationRepo.ListRoleRequests(status)
+}
+
+// Approve 批准申请并授予角色，管理员不能批准自己的申请
+func (s *InvitationService) Approve(requestID, adminID int, note string) (*model.User, error) {
+	request, err := s.InvitationRepo.GetRoleRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.UserID == adminID {
+		return nil, errors.New("cannot approve own request")
+	}
+	if request.Status != model.RoleRequestPending {
+		return nil, errors.New("role request already decided")
+	}
+
+	//先授予角色再标记为已批准，授予失败时申请保持待审批，可以重试
+	user, err := s.roleService.GrantRole(request.UserID, request.Role, model.GrantSourceApproval, request.ID, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.InvitationRepo.DecideRoleRequest(requestID, model.RoleRequestApproved, adminID, note); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *InvitationService) Reject(requestID, adminID int, note string) error {
+	return s.InvitationRepo.DecideRoleRequest(requestID, model.RoleRequestRejected, adminID, note)
+}
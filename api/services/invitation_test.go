@@ -0,0 +1,88 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/api/dao/cache"
+	"GoGin/api/dao/memory"
+	"GoGin/internal/model"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestInvitationService(userService *UserService) *InvitationService {
+	roleRepo := memory.NewMemoryRoleRepo()
+	roleService := NewRoleService(roleRepo, userService.UserRepo, userService.GrantRepo, userService)
+	return NewInvitationService(userService.InvitationRepo, roleRepo, roleService)
+}
+
+// 没有管理员时签发一次性邀请码，注册后不再签发
+func TestBootstrapAdminInvitation(t *testing.T) {
+	userService := newTestUserService(newTestConfig(), cache.NewMemoryCache(1000))
+	s := newTestInvitationService(userService)
+
+	code, err := s.BootstrapAdminInvitation(time.Hour)
+	if err != nil || code == "" {
+		t.Fatalf("BootstrapAdminInvitation = %q, %v, want a code", code, err)
+	}
+	admin, err := userService.Register(&model.RegisterRequest{Username: "root", Password: "correcthorse42", Email: "root@school.edu", InviteCode: code})
+	if err != nil {
+		t.Fatalf("Register with bootstrap code: %v", err)
+	}
+	if admin.Role != model.RoleAdmin {
+		t.Fatalf("role = %q, want %q", admin.Role, model.RoleAdmin)
+	}
+	if _, err := userService.Register(&model.RegisterRequest{Username: "eve", Password: "correcthorse42", Email: "eve@school.edu", InviteCode: code}); err == nil {
+		t.Fatal("bootstrap code accepted twice")
+	}
+
+	code, err = s.BootstrapAdminInvitation(time.Hour)
+	if err != nil || code != "" {
+		t.Fatalf("BootstrapAdminInvitation with an admin = %q, %v, want empty", code, err)
+	}
+}
+
+// failingGrantRepo 模拟授予记录写入失败
+type failingGrantRepo struct {
+	dao.RoleGrantRepository
+}
+
+func (failingGrantRepo) RecordGrant(*model.RoleGrant) error {
+	return errors.New("connection refused")
+}
+
+// 授予角色失败时申请保持待审批，修复后可以再次批准
+func TestApproveKeepsRequestPendingWhenGrantFails(t *testing.T) {
+	userService := newTestUserService(newTestConfig(), cache.NewMemoryCache(1000))
+	s := newTestInvitationService(userService)
+	user, err := userService.Register(&model.RegisterRequest{Username: "bob", Password: "correcthorse42", Email: "bob@school.edu"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	request, err := s.RequestRole(user.UserID, model.RoleRequestRequest{Role: model.RoleInstructor})
+	if err != nil {
+		t.Fatalf("RequestRole: %v", err)
+	}
+
+	grantRepo := s.roleService.GrantRepo
+	s.roleService.GrantRepo = failingGrantRepo{}
+	if _, err := s.Approve(request.ID, 99, ""); err == nil {
+		t.Fatal("Approve succeeded although the grant failed")
+	}
+	stored, err := s.InvitationRepo.GetRoleRequest(request.ID)
+	if err != nil || stored.Status != model.RoleRequestPending {
+		t.Fatalf("request after failed grant = %+v, %v, want pending", stored, err)
+	}
+
+	s.roleService.GrantRepo = grantRepo
+	approved, err := s.Approve(request.ID, 99, "")
+	if err != nil {
+		t.Fatalf("Approve retry: %v", err)
+	}
+	if approved.Role != model.RoleInstructor {
+		t.Fatalf("role = %q, want %q", approved.Role, model.RoleInstructor)
+	}
+	if _, err := s.Approve(request.ID, 99, ""); err == nil {
+		t.Fatal("Approve accepted an already decided request")
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"GoGin/internal/config"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeAttemptRepo 只记录次数和封禁时长，不按时间过期
+type fakeAttemptRepo struct {
+	counts  map[string]int64
+	blocked map[string]time.Duration
+}
+
+func newFakeAttemptRepo() *fakeAttemptRepo {
+	return &fakeAttemptRepo{counts: make(map[string]int64), blocked: make(map[string]time.Duration)}
+}
+
+func (r *fakeAttemptRepo) RecordFailure(key string, _ time.Duration) (int64, error) {
+	r.counts[key]++
+	return r.counts[key], nil
+}
+
+func (r *fakeAttemptRepo) Block(key string, d time.Duration) error {
+	r.blocked[key] = d
+	return nil
+}
+
+func (r *fakeAttemptRepo) BlockedFor(key string) (time.Duration, error) {
+	return r.blocked[key], nil
+}
+
+func (r *fakeAttemptRepo) Reset(key string) error {
+	delete(r.counts, key)
+	delete(r.blocked, key)
+	return nil
+}
+
+var testThrottleConfig = config.LoginThrottleConfig{
+	AccountDelayAfter:       3,
+	AccountLockoutThreshold: 10,
+	IPDelayAfter:            20,
+	IPLockoutThreshold:      100,
+	BaseDelaySeconds:        1,
+	MaxDelaySeconds:         60,
+	LockoutMinutes:          15,
+	WindowMinutes:           15,
+}
+
+func TestLoginThrottleDelay(t *testing.T) {
+	throttle := &LoginThrottle{cfg: testThrottleConfig}
+	tests := []struct {
+		count int
+		want  time.Duration
+	}{
+		{1, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{8, 32 * time.Second},
+		{9, 60 * time.Second},
+		{10, 15 * time.Minute},
+		{50, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := throttle.delay(tt.count, 3, 10); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.count, got, tt.want)
+		}
+	}
+
+	// 阈值为 0 表示不启用
+	if got := throttle.delay(100, 0, 0); got != 0 {
+		t.Errorf("delay with rules disabled = %v, want 0", got)
+	}
+}
+
+func TestLoginThrottleFailAndSucceed(t *testing.T) {
+	repo := newFakeAttemptRepo()
+	throttle := &LoginThrottle{AttemptRepo: repo, cfg: testThrottleConfig}
+	accountKey, ipKey := AccountKey(1, ""), IPKey("10.0.0.1")
+
+	for i := 0; i < 2; i++ {
+		throttle.Fail(accountKey, ipKey)
+	}
+	if err := throttle.Check(accountKey, ipKey); err != nil {
+		t.Fatalf("Check after 2 failures = %v, want nil", err)
+	}
+
+	throttle.Fail(accountKey, ipKey)
+	var throttled *ThrottledError
+	if err := throttle.Check(accountKey, ipKey); !errors.As(err, &throttled) || throttled.RetryAfter != time.Second {
+		t.Fatalf("Check after 3 failures = %v, want ThrottledError with 1s", err)
+	}
+
+	// 成功只清除账号计数，IP 计数保留
+	throttle.Succeed(accountKey)
+	if err := throttle.Check(accountKey, ""); err != nil {
+		t.Fatalf("Check after Succeed = %v, want nil", err)
+	}
+	if repo.counts[ipKey] != 3 {
+		t.Fatalf("ip failures = %d, want 3", repo.counts[ipKey])
+	}
+}
+
+func TestAccountKeySharedByLoginNames(t *testing.T) {
+	if AccountKey(7, "alice") != AccountKey(7, "alice@school.edu") {
+		t.Fatal("username and email login of the same account must share one counter")
+	}
+	if AccountKey(0, " Alice ") != AccountKey(0, "alice") {
+		t.Fatal("unknown login names must be normalized")
+	}
+}
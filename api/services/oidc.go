@@ -0,0 +1,242 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/config"
+	"GoGin/internal/model"
+	"GoGin/internal/oidc"
+	"GoGin/internal/util"
+	"GoGin/internal/util/jwt_util"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+// OIDCResult 回调结果：绑定请求只返回 Linked，登录请求返回 Login
+type OIDCResult struct {
+	Linked   bool
+	Identity *model.UserIdentity
+	Login    *LoginResult
+}
+
+// OIDCService 外部身份提供方登录（授权码 + PKCE）与身份绑定
+type OIDCService struct {
+	Providers    *oidc.Registry
+	IdentityRepo dao.IdentityRepository
+	StateRepo    dao.OIDCStateRepository
+	UserRepo     dao.UserRepository
+	userService  *UserService
+	autoRegister bool
+}
+
+func NewOIDCService(providers *oidc.Registry, identityRepo dao.IdentityRepository, stateRepo dao.OIDCStateRepository, userRepo dao.UserRepository, userService *UserService, cfg *config.Config) *OIDCService {
+	return &OIDCService{
+		Providers:    providers,
+		IdentityRepo: identityRepo,
+		StateRepo:    stateRepo,
+		UserRepo:     userRepo,
+		userService:  userService,
+		autoRegister: cfg.OIDCAutoRegister,
+	}
+}
+
+// AuthURL 生成授权地址；linkUserID 非 0 时回调会把身份绑定到该用户
+func (s *OIDCService) AuthURL(ctx context.Context, providerName string, linkUserID int) (string, error) {
+	provider, err := s.Providers.Get(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := oidc.NewState()
+	if err != nil {
+		return "", errors.New("state generate failed")
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return "", errors.New("state generate failed")
+	}
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		return "", errors.New("state generate failed")
+	}
+
+	if err := s.StateRepo.SaveState(state, &model.OIDCState{
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		LinkUserID:   linkUserID,
+	}, oidcStateTTL); err != nil {
+		return "", err
+	}
+	return provider.AuthCodeURL(ctx, state, nonce, oidc.S256Challenge(verifier))
+}
+
+// Callback 校验 state，换取并校验 ID Token，然后绑定身份或登录
+func (s *OIDCService) Callback(ctx context.Context, providerName, code, state string, client model.ClientInfo) (*OIDCResult, error) {
+	provider, err := s.Providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.StateRepo.ConsumeState(state)
+	if err != nil {
+		return nil, err
+	}
+	if saved.Provider != providerName {
+		return nil, errors.New("state invalid or expired")
+	}
+
+	token, err := provider.Exchange(ctx, code, saved.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := provider.VerifyIDToken(ctx, token.IDToken, saved.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if saved.LinkUserID != 0 {
+		identity, err := s.link(saved.LinkUserID, providerName, idToken)
+		if err != nil {
+			return nil, err
+		}
+		return &OIDCResult{Linked: true, Identity: identity}, nil
+	}
+
+	user, err := s.resolveUser(providerName, idToken)
+	if err != nil {
+		return nil, err
+	}
+	login, err := s.userService.finishLogin(user, []string{jwt_util.AMROIDC}, client)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCResult{Login: login}, nil
+}
+
+func (s *OIDCService) ListIdentities(userID int) ([]model.UserIdentity, error) {
+	return s.IdentityRepo.ListUserIdentities(userID)
+}
+
+// Unlink 解绑身份；没有密码的账号至少保留一个外部身份，密码哈希不经过缓存，从数据库读取
+func (s *OIDCService) Unlink(userID, identityID int) error {
+	user, err := s.UserRepo.SelectCredentials(userID)
+	if err != nil {
+		return err
+	}
+	if user.Password == "" {
+		identities, err := s.IdentityRepo.ListUserIdentities(userID)
+		if err != nil {
+			return err
+		}
+		if len(identities) <= 1 {
+			return errors.New("cannot unlink the only sign-in method, set a password first")
+		}
+	}
+	return s.IdentityRepo.DeleteIdentity(userID, identityID)
+}
+
+func (s *OIDCService) link(userID int, providerName string, idToken *oidc.IDToken) (*model.UserIdentity, error) {
+	if existing, err := s.IdentityRepo.GetIdentity(providerName, idToken.Subject); err == nil {
+		if existing.UserID != userID {
+			return nil, errors.New("identity already linked to another account")
+		}
+		return existing, nil
+	}
+
+	identity := &model.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  idToken.Subject,
+		Email:    idToken.Email,
+	}
+	if err := s.IdentityRepo.CreateIdentity(identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// resolveUser 已绑定的身份直接登录；否则按已验证邮箱匹配本地用户，找不到时按配置自动注册
+func (s *OIDCService) resolveUser(providerName string, idToken *oidc.IDToken) (*model.User, error) {
+	if identity, err := s.IdentityRepo.GetIdentity(providerName, idToken.Subject); err == nil {
+		return s.UserRepo.SelectByID(identity.UserID)
+	}
+
+	if idToken.Email == "" || !idToken.EmailVerified {
+		return nil, errors.New("identity provider did not return a verified email")
+	}
+
+	user, err := s.UserRepo.SelectByEmail(idToken.Email)
+	if err == nil && user.UserID != 0 {
+		// 本地邮箱未验证时不自动绑定，防止他人抢注该邮箱后接管外部身份
+		if !user.EmailVerified {
+			return nil, errors.New("email not verified locally, log in with password and link this identity")
+		}
+	} else {
+		if !s.autoRegister {
+			return nil, errors.New("no account for this identity")
+		}
+		if user, err = s.register(idToken); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.link(user.UserID, providerName, idToken); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// register 自动注册的用户没有密码，只能通过外部身份或重置密码登录
+func (s *OIDCService) register(idToken *oidc.IDToken) (*model.User, error) {
+	base := usernameFromIdentity(idToken)
+	username := base
+	for i := 0; i < 5; i++ {
+		user := &model.User{
+			Username:      username,
+			Email:         idToken.Email,
+			Role:          model.RoleUser,
+			EmailVerified: true,
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+
+		err := s.UserRepo.AddUser(user)
+		if err == nil {
+			return user, nil
+		}
+		if err.Error() != "user already exists" {
+			return nil, err
+		}
+
+		suffix, err := util.RandomID(2)
+		if err != nil {
+			return nil, errors.New("username generate failed")
+		}
+		username = base + "_" + suffix
+	}
+	return nil, errors.New("username generate failed")
+}
+
+func usernameFromIdentity(idToken *oidc.IDToken) string {
+	candidate := idToken.PreferredUsername
+	if candidate == "" {
+		candidate, _, _ = strings.Cut(idToken.Email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range candidate {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "user"
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"GoGin/api/dao/cache"
+	"GoGin/api/dao/memory"
+	"GoGin/internal/config"
+	"GoGin/internal/model"
+	"GoGin/internal/oidc"
+	"GoGin/internal/oidc/oidctest"
+	"context"
+	"net/url"
+	"testing"
+)
+
+const testRedirectURL = "http://localhost:8080/oidc/school/callback"
+
+func newTestOIDCService(t *testing.T, srv *oidctest.Server) *OIDCService {
+	t.Helper()
+
+	cfg := newTestConfig()
+	cfg.OIDCProviders = []config.OIDCProviderConfig{srv.ProviderConfig("school", testRedirectURL)}
+	cfg.OIDCAutoRegister = true
+	cacheClient := cache.NewMemoryCache(1000)
+	userService := newTestUserService(cfg, cacheClient)
+
+	return NewOIDCService(oidc.NewRegistry(cfg.OIDCProviders, nil), memory.NewMemoryIdentityRepo(),
+		cache.NewCacheOIDCStateRepo(cacheClient), userService.UserRepo, userService, cfg)
+}
+
+func TestOIDCLoginFlow(t *testing.T) {
+	srv := oidctest.NewServer("client-id")
+	defer srv.Close()
+	srv.SetUser(oidctest.User{Subject: "u1", Email: "alice@school.edu", EmailVerified: true, PreferredUsername: "alice"})
+
+	s := newTestOIDCService(t, srv)
+	ctx := context.Background()
+
+	authURL, err := s.AuthURL(ctx, "school", 0)
+	if err != nil {
+		t.Fatalf("AuthURL: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("nonce") == "" {
+		t.Fatalf("auth url missing PKCE or nonce: %s", authURL)
+	}
+
+	code, state, err := srv.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if state != query.Get("state") {
+		t.Fatalf("state = %q, want %q", state, query.Get("state"))
+	}
+
+	result, err := s.Callback(ctx, "school", code, state, model.ClientInfo{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if result.Login == nil || result.Login.Token == "" || result.Login.RefreshToken == "" {
+		t.Fatalf("Callback did not log in: %+v", result)
+	}
+	if result.Login.User.Username != "alice" || result.Login.User.Email != "alice@school.edu" {
+		t.Fatalf("registered user = %+v", result.Login.User)
+	}
+
+	identities, err := s.ListIdentities(result.Login.User.UserID)
+	if err != nil || len(identities) != 1 || identities[0].Subject != "u1" {
+		t.Fatalf("identities = %+v, err = %v", identities, err)
+	}
+
+	// state 只能使用一次
+	if _, err := s.Callback(ctx, "school", code, state, model.ClientInfo{}); err == nil {
+		t.Fatal("Callback accepted a consumed state")
+	}
+
+	// 再次登录使用已绑定的身份，不会重复注册
+	authURL, err = s.AuthURL(ctx, "school", 0)
+	if err != nil {
+		t.Fatalf("AuthURL: %v", err)
+	}
+	code, state, err = srv.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	again, err := s.Callback(ctx, "school", code, state, model.ClientInfo{})
+	if err != nil {
+		t.Fatalf("second Callback: %v", err)
+	}
+	if again.Login == nil || again.Login.User.UserID != result.Login.User.UserID {
+		t.Fatalf("second login user = %+v, want user %d", again.Login, result.Login.User.UserID)
+	}
+}
+
+func TestOIDCCallbackRejectsUnknownState(t *testing.T) {
+	srv := oidctest.NewServer("client-id")
+	defer srv.Close()
+	srv.SetUser(oidctest.User{Subject: "u1", Email: "alice@school.edu", EmailVerified: true})
+
+	s := newTestOIDCService(t, srv)
+	ctx := context.Background()
+
+	authURL, err := s.AuthURL(ctx, "school", 0)
+	if err != nil {
+		t.Fatalf("AuthURL: %v", err)
+	}
+	code, _, err := srv.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if _, err := s.Callback(ctx, "school", code, "forged-state", model.ClientInfo{}); err == nil {
+		t.Fatal("Callback accepted an unknown state")
+	}
+}
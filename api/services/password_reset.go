@@ -0,0 +1,80 @@
+package services
+
+import (
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ForgotPassword 发送重置密码邮件；邮箱不存在、请求过于频繁或发送失败时同样返回成功，避免暴露账号是否存在。
+// 按邮箱和 IP 限制请求次数，之前发出的链接在过期前仍然有效，避免他人反复请求使真正的链接失效
+func (s *UserService) ForgotPassword(email string, client model.ClientInfo) error {
+	if !s.Throttle.Allow("reset:ip:"+client.IP, s.cfg.LoginThrottle.ResetPerIP) ||
+		!s.Throttle.Allow("reset:email:"+strings.ToLower(strings.TrimSpace(email)), s.cfg.LoginThrottle.ResetPerEmail) {
+		log.Println("password reset rate limited:", client.IP)
+		return nil
+	}
+
+	user, err := s.UserRepo.SelectByEmail(email)
+	if err != nil || user.UserID == 0 {
+		return nil
+	}
+	if err := s.sendPasswordReset(user); err != nil {
+		log.Println("send password reset failed:", err)
+	}
+	return nil
+}
+
+func (s *UserService) sendPasswordReset(user *model.User) error {
+	rawToken, err := util.RandomToken(32)
+	if err != nil {
+		return errors.New("token generate failed")
+	}
+	ttl := time.Duration(s.cfg.PasswordResetTTLMinutes) * time.Minute
+	token := &model.OneTimeToken{
+		UserID:    user.UserID,
+		Purpose:   model.TokenPurposePasswordReset,
+		TokenHash: util.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.TokenRepo.CreateToken(token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/user/password/reset?token=%s", s.cfg.AppBaseURL, rawToken)
+	body := fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires in %d minutes and can only be used once.\n\n%s\n\nReset token: %s\n\nIf you did not request this, you can ignore this email.",
+		user.Username, s.cfg.PasswordResetTTLMinutes, link, rawToken)
+	return s.mailer.Send(user.Email, "Reset your password", body)
+}
+
+// ResetPassword 使用一次性令牌重置密码，并使该用户所有会话失效
+func (s *UserService) ResetPassword(req model.ResetPasswordRequest) error {
+	//密码是否符合策略
+	if err := s.passwordPolicy.Validate(req.NewPassword); err != nil {
+		return err
+	}
+
+	token, err := s.TokenRepo.ConsumeToken(model.TokenPurposePasswordReset, util.HashToken(req.Token))
+	if err != nil {
+		return err
+	}
+
+	//加密密码
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return errors.New("password hash failed")
+	}
+	if err := s.UserRepo.UpdatePassword(token.UserID, hashedPassword); err != nil {
+		return err
+	}
+
+	//其余未使用的重置链接全部作废
+	if err := s.TokenRepo.DeleteUserTokens(token.UserID, model.TokenPurposePasswordReset); err != nil {
+		log.Println("delete password reset tokens failed:", err)
+	}
+	return s.InvalidateUserTokens(token.UserID)
+}
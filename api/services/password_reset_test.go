@@ -0,0 +1,63 @@
+package services
+
+import (
+	"GoGin/api/dao/cache"
+	"GoGin/internal/model"
+	"strings"
+	"testing"
+)
+
+// captureMailer 记录发出的邮件正文
+type captureMailer struct {
+	bodies []string
+}
+
+func (m *captureMailer) Send(_, _, body string) error {
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+func resetToken(t *testing.T, body string) string {
+	t.Helper()
+	_, token, ok := strings.Cut(body, "Reset token: ")
+	if !ok {
+		t.Fatalf("no reset token in %q", body)
+	}
+	token, _, _ = strings.Cut(token, "\n")
+	return token
+}
+
+func TestForgotPasswordRateLimited(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.LoginThrottle = testThrottleConfig
+	cfg.LoginThrottle.ResetPerEmail = 2
+	cfg.PasswordResetTTLMinutes = 30
+	s := newTestUserService(cfg, cache.NewMemoryCache(1000))
+	if _, err := s.Register(&model.RegisterRequest{Username: "alice", Password: "correcthorse42", Email: "alice@school.edu"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	mails := &captureMailer{}
+	s.mailer = mails
+
+	client := model.ClientInfo{IP: "10.0.0.1"}
+	for i := 0; i < 3; i++ {
+		if err := s.ForgotPassword("alice@school.edu", client); err != nil {
+			t.Fatalf("ForgotPassword #%d: %v", i+1, err)
+		}
+	}
+	if err := s.ForgotPassword("nobody@school.edu", client); err != nil {
+		t.Fatalf("ForgotPassword for unknown email: %v", err)
+	}
+	if len(mails.bodies) != 2 {
+		t.Fatalf("sent %d reset mails, want 2", len(mails.bodies))
+	}
+
+	// 新的请求不会使之前的链接失效；重置成功后其余链接全部作废
+	first, second := resetToken(t, mails.bodies[0]), resetToken(t, mails.bodies[1])
+	if err := s.ResetPassword(model.ResetPasswordRequest{Token: first, NewPassword: "batterystaple42"}); err != nil {
+		t.Fatalf("ResetPassword with the first link: %v", err)
+	}
+	if err := s.ResetPassword(model.ResetPasswordRequest{Token: second, NewPassword: "batterystaple43"}); err == nil {
+		t.Fatal("ResetPassword accepted a link issued before the reset")
+	}
+}
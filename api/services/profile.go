@@ -0,0 +1,128 @@
+package services
+
+import (
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// ChangePassword 校验当前密码后修改，成功后其他会话全部失效
+func (s *UserService) ChangePassword(userID int, req model.ChangePasswordRequest) error {
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.reauthenticate(user, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	if err := s.passwordPolicy.Validate(req.NewPassword); err != nil {
+		return err
+	}
+	hashedPassword, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return errors.New("password hash failed")
+	}
+	if err := s.UserRepo.UpdatePassword(userID, hashedPassword); err != nil {
+		return err
+	}
+
+	return s.InvalidateUserTokens(userID)
+}
+
+// ChangeUsername 用户名唯一
+func (s *UserService) ChangeUsername(userID int, req model.ChangeUsernameRequest) (*model.User, error) {
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		return nil, errors.New("username format Error")
+	}
+	if err := s.UserRepo.UpdateUsername(userID, username); err != nil {
+		return nil, err
+	}
+	return s.UserRepo.SelectByID(userID)
+}
+
+// RequestEmailChange 校验密码后向新邮箱发送确认链接，确认前邮箱不变
+func (s *UserService) RequestEmailChange(userID int, req model.ChangeEmailRequest) error {
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.reauthenticate(user, req.Password); err != nil {
+		return err
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if !strings.Contains(newEmail, "@") {
+		return errors.New("email format Error")
+	}
+	if newEmail == user.Email {
+		return errors.New("email unchanged")
+	}
+	if existing, err := s.UserRepo.SelectByEmail(newEmail); err == nil && existing.UserID != 0 {
+		return errors.New("email already exists")
+	}
+
+	if err := s.TokenRepo.DeleteUserTokens(userID, model.TokenPurposeChangeEmail); err != nil {
+		return err
+	}
+	rawToken, err := util.RandomToken(32)
+	if err != nil {
+		return errors.New("token generate failed")
+	}
+	token := &model.OneTimeToken{
+		UserID:    userID,
+		Purpose:   model.TokenPurposeChangeEmail,
+		TokenHash: util.HashToken(rawToken),
+		Payload:   newEmail,
+		ExpiresAt: time.Now().Add(time.Duration(s.cfg.Verification.TTLHours) * time.Hour),
+	}
+	if err := s.TokenRepo.CreateToken(token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/user/email/confirm?token=%s", s.cfg.AppBaseURL, rawToken)
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your new email address by opening the link below. It expires in %d hours.\n\n%s",
+		user.Username, s.cfg.Verification.TTLHours, link)
+	if err := s.mailer.Send(newEmail, "Confirm your new email", body); err != nil {
+		return errors.New("mail send failed")
+	}
+
+	//通知旧邮箱，失败不影响流程
+	notice := fmt.Sprintf("Hi %s,\n\nA request was made to change the email address of your account to %s. If this wasn't you, change your password immediately.",
+		user.Username, newEmail)
+	if err := s.mailer.Send(user.Email, "Email change requested", notice); err != nil {
+		log.Println("send email change notice failed:", err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange 新邮箱收到的一次性令牌确认后生效
+func (s *UserService) ConfirmEmailChange(rawToken string) error {
+	token, err := s.TokenRepo.ConsumeToken(model.TokenPurposeChangeEmail, util.HashToken(rawToken))
+	if err != nil {
+		return err
+	}
+	return s.UserRepo.UpdateEmail(token.UserID, token.Payload)
+}
+
+// reauthenticate 敏感操作前重新校验密码，失败计入登录限流；缓存中的用户不含密码哈希，从数据库读取
+func (s *UserService) reauthenticate(user *model.User, password string) error {
+	accountKey := AccountKey(user.UserID, "")
+	if err := s.Throttle.Check(accountKey, ""); err != nil {
+		return err
+	}
+	credentials, err := s.UserRepo.SelectCredentials(user.UserID)
+	if err != nil {
+		return err
+	}
+	if ok, _ := s.hasher.Verify(credentials.Password, password); !ok {
+		s.Throttle.Fail(accountKey, "")
+		return ErrInvalidCredentials
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+)
+
+type RoleService struct {
+	RoleRepo    dao.RoleRepository
+	UserRepo    dao.UserRepository
+	GrantRepo   dao.RoleGrantRepository
+	userService *UserService
+}
+
+func NewRoleService(roleRepo dao.RoleRepository, userRepo dao.UserRepository, grantRepo dao.RoleGrantRepository, userService *UserService) *RoleService {
+	return &RoleService{
+		RoleRepo:    roleRepo,
+		UserRepo:    userRepo,
+		GrantRepo:   grantRepo,
+		userService: userService,
+	}
+}
+
+func (s *RoleService) ListRoles() ([]model.Role, error) {
+	return s.RoleRepo.ListRoles()
+}
+
+func (s *RoleService) ListPermissions() ([]model.Permission, error) {
+	return s.RoleRepo.ListPermissions()
+}
+
+func (s *RoleService) CreateRole(req model.CreateRoleRequest) (*model.Role, error) {
+	if _, err := s.RoleRepo.GetRole(req.Name); err == nil {
+		return nil, errors.New("role already exists")
+	}
+
+	role := &model.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.RoleRepo.CreateRole(role, req.Permissions); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *RoleService) SetRolePermissions(roleName string, permissions []string) (*model.Role, error) {
+	if err := s.RoleRepo.SetPermissions(roleName, permissions); err != nil {
+		return nil, err
+	}
+	return s.RoleRepo.GetRole(roleName)
+}
+
+// AssignRole 管理员直接修改用户角色
+func (s *RoleService) AssignRole(adminID, userID int, roleName string) (*model.User, error) {
+	return s.GrantRole(userID, roleName, model.GrantSourceAdmin, 0, adminID)
+}
+
+// GrantRole 修改用户角色并记录授予来源，同时使其现有令牌失效以便新角色立即生效
+func (s *RoleService) GrantRole(userID int, roleName, source string, sourceID, grantedBy int) (*model.User, error) {
+	if _, err := s.RoleRepo.GetRole(roleName); err != nil {
+		return nil, err
+	}
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	previousRole := user.Role
+
+	if err := s.UserRepo.UpdateRole(userID, roleName); err != nil {
+		return nil, err
+	}
+	if err := s.GrantRepo.RecordGrant(&model.RoleGrant{
+		UserID:       userID,
+		Role:         roleName,
+		PreviousRole: previousRole,
+		Source:       source,
+		SourceID:     sourceID,
+		GrantedBy:    grantedBy,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.userService.InvalidateUserTokens(userID); err != nil {
+		return nil, err
+	}
+	return s.UserRepo.SelectByID(userID)
+}
+
+// ListGrants 角色授予记录，userID 为 0 时返回全部
+func (s *RoleService) ListGrants(userID int) ([]model.RoleGrant, error) {
+	return s.GrantRepo.ListGrants(userID)
+}
+
+// HasPermission 供权限中间件调用
+func (s *RoleService) HasPermission(roleName, permission string) (bool, error) {
+	permissions, err := s.RoleRepo.GetPermissions(roleName)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range permissions {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
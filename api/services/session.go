@@ -0,0 +1,60 @@
+package services
+
+import (
+	"GoGin/internal/model"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxUserAgentLength 与 sessions.user_agent 列宽一致
+const maxUserAgentLength = 255
+
+// ListSessions 列出当前用户已登录的设备，currentSessionID 对应的会话标记为当前会话
+func (s *UserService) ListSessions(userID int, currentSessionID string) ([]model.Session, error) {
+	sessions, err := s.SessionRepo.ListUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeUserSession 下线指定设备：刷新令牌和该会话签发的访问令牌都失效
+func (s *UserService) RevokeUserSession(userID int, sessionID string) error {
+	session, err := s.SessionRepo.GetSession(sessionID)
+	if err != nil || session.UserID != userID {
+		return errors.New("session not found")
+	}
+	return s.revokeSession(sessionID)
+}
+
+// TouchSession 记录会话最近一次使用，失败只记录日志
+func (s *UserService) TouchSession(sessionID string, client model.ClientInfo) {
+	if sessionID == "" {
+		return
+	}
+	if err := s.SessionRepo.TouchSession(sessionID, normalizeClient(client), time.Now()); err != nil {
+		log.Println("session touch failed:", err)
+	}
+}
+
+func (s *UserService) revokeSession(sessionID string) error {
+	if err := s.SessionRepo.RevokeSession(sessionID); err != nil {
+		return err
+	}
+	if err := s.RevocationRepo.RevokeSessionTokens(sessionID); err != nil {
+		return errors.New("access token revoke failed")
+	}
+	return nil
+}
+
+func normalizeClient(client model.ClientInfo) model.ClientInfo {
+	if len(client.UserAgent) > maxUserAgentLength {
+		client.UserAgent = strings.ToValidUTF8(client.UserAgent[:maxUserAgentLength], "")
+	}
+	return client
+}
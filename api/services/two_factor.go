@@ -0,0 +1,225 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/config"
+	"GoGin/internal/model"
+	"GoGin/internal/util"
+	"GoGin/internal/util/jwt_util"
+	"GoGin/internal/util/totp"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	recoveryCodeCount = 10
+	challengeTTL      = 5 * time.Minute
+)
+
+type TwoFactorService struct {
+	TwoFactorRepo dao.TwoFactorRepository
+	UserRepo      dao.UserRepository
+	Throttle      *LoginThrottle
+	jwtUtil       jwt_util.Util
+	issuer        string
+}
+
+func NewTwoFactorService(twoFactorRepo dao.TwoFactorRepository, userRepo dao.UserRepository, throttle *LoginThrottle, jwtUtil jwt_util.Util, cfg *config.Config) *TwoFactorService {
+	return &TwoFactorService{
+		TwoFactorRepo: twoFactorRepo,
+		UserRepo:      userRepo,
+		Throttle:      throttle,
+		jwtUtil:       jwtUtil,
+		issuer:        cfg.TOTPIssuer,
+	}
+}
+
+// Setup 生成新的 TOTP 密钥，确认前不生效
+func (s *TwoFactorService) Setup(userID int) (string, string, error) {
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", errors.New("secret generate failed")
+	}
+	if err := s.TwoFactorRepo.SaveTwoFactor(&model.TwoFactor{
+		UserID: userID,
+		Secret: secret,
+	}); err != nil {
+		return "", "", err
+	}
+
+	return secret, totp.URI(s.issuer, user.Email, secret), nil
+}
+
+// Confirm 用第一个验证码确认绑定，返回只展示一次的恢复码
+func (s *TwoFactorService) Confirm(userID int, code string) ([]string, error) {
+	twoFactor, err := s.TwoFactorRepo.GetTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, errors.New("two-factor already enabled")
+	}
+	if err := s.verifyTOTP(twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.TwoFactorRepo.EnableTwoFactor(userID); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable 关闭双因素认证需要提供当前验证码或恢复码
+func (s *TwoFactorService) Disable(userID int, code string, client model.ClientInfo) error {
+	if err := s.verifyCodeThrottled(userID, code, client); err != nil {
+		return err
+	}
+	return s.TwoFactorRepo.DisableTwoFactor(userID)
+}
+
+// RegenerateRecoveryCodes 旧恢复码全部作废
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID int, code string, client model.ClientInfo) ([]string, error) {
+	if err := s.verifyCodeThrottled(userID, code, client); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(userID)
+}
+
+// StepUp 已登录用户再次校验验证码，换取带 amr=otp 的访问令牌，新令牌仍属于原会话
+func (s *TwoFactorService) StepUp(userID int, sessionID, code string, client model.ClientInfo) (string, error) {
+	if err := s.verifyCodeThrottled(userID, code, client); err != nil {
+		return "", err
+	}
+
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return "", err
+	}
+	claims := newAccessClaims(user, []string{jwt_util.AMRPassword, jwt_util.AMROTP})
+	claims.SessionID = sessionID
+	token, err := s.jwtUtil.GenerateToken(claims)
+	if err != nil {
+		return "", errors.New("token generate failed")
+	}
+	return token, nil
+}
+
+// IsEnabled 查询失败时返回错误，调用方不能当作未开启处理
+func (s *TwoFactorService) IsEnabled(userID int) (bool, error) {
+	twoFactor, err := s.TwoFactorRepo.GetTwoFactor(userID)
+	if errors.Is(err, dao.ErrTwoFactorNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return twoFactor.Enabled, nil
+}
+
+// NewChallenge 第一步认证通过后签发的短期挑战令牌，只能用于第二步登录；amr 记录第一步的认证方式
+func (s *TwoFactorService) NewChallenge(user *model.User, amr []string) (string, error) {
+	token, err := s.jwtUtil.GenerateToken(&jwt_util.Claims{
+		TokenType: jwt_util.TokenTypeMFAChallenge,
+		UserID:    user.UserID,
+		Username:  user.Username,
+		AMR:       amr,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(challengeTTL)),
+		},
+	})
+	if err != nil {
+		return "", errors.New("token generate failed")
+	}
+	return token, nil
+}
+
+func (s *TwoFactorService) VerifyChallenge(challengeToken string) (int, []string, error) {
+	token, err := s.jwtUtil.ValidateToken(challengeToken)
+	if err != nil {
+		return 0, nil, errors.New("challenge token invalid")
+	}
+	claims, err := s.jwtUtil.ExtractClaims(token)
+	if err != nil || claims.TokenType != jwt_util.TokenTypeMFAChallenge {
+		return 0, nil, errors.New("challenge token invalid")
+	}
+	return claims.UserID, claims.AMR, nil
+}
+
+// verifyCode 接受 6 位 TOTP 验证码或一次性恢复码
+func (s *TwoFactorService) verifyCode(userID int, code string) error {
+	twoFactor, err := s.TwoFactorRepo.GetTwoFactor(userID)
+	if err != nil || !twoFactor.Enabled {
+		return errors.New("two-factor not enabled")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.verifyTOTP(twoFactor, code)
+	}
+	return s.TwoFactorRepo.UseRecoveryCode(userID, hashRecoveryCode(code))
+}
+
+// verifyCodeThrottled 验证码失败与密码失败共用账号和 IP 计数，防止穷举 6 位验证码
+func (s *TwoFactorService) verifyCodeThrottled(userID int, code string, client model.ClientInfo) error {
+	accountKey, ipKey := AccountKey(userID, ""), IPKey(client.IP)
+	if err := s.Throttle.Check(accountKey, ipKey); err != nil {
+		return err
+	}
+	if err := s.verifyCode(userID, code); err != nil {
+		s.Throttle.Fail(accountKey, ipKey)
+		return err
+	}
+	s.Throttle.Succeed(accountKey)
+	return nil
+}
+
+func (s *TwoFactorService) verifyTOTP(twoFactor *model.TwoFactor, code string) error {
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), 1)
+	if !ok {
+		return errors.New("invalid verification code")
+	}
+	used, err := s.TwoFactorRepo.UseStep(twoFactor.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New("verification code already used")
+	}
+	return nil
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(userID int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := util.RandomID(5)
+		if err != nil {
+			return nil, errors.New("recovery code generate failed")
+		}
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.TwoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return util.HashToken(normalized)
+}
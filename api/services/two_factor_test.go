@@ -0,0 +1,47 @@
+package services
+
+import (
+	"GoGin/api/dao/cache"
+	"GoGin/internal/model"
+	"GoGin/internal/util/totp"
+	"errors"
+	"testing"
+	"time"
+)
+
+// 已登录后的验证码校验（step-up、关闭、重置恢复码）同样计入失败次数
+func TestStepUpThrottled(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.LoginThrottle = testThrottleConfig
+	s := newTestUserService(cfg, cache.NewMemoryCache(1000))
+	user, err := s.Register(&model.RegisterRequest{Username: "alice", Password: "correcthorse42", Email: "alice@school.edu"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	secret, _, err := s.TwoFactor.Setup(user.UserID)
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	if _, err := s.TwoFactor.Confirm(user.UserID, code); err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+
+	client := model.ClientInfo{IP: "10.0.0.1"}
+	for i := 0; i < testThrottleConfig.AccountDelayAfter; i++ {
+		if _, err := s.TwoFactor.StepUp(user.UserID, "sid", "00000-00000", client); err == nil {
+			t.Fatal("StepUp accepted an invalid code")
+		}
+	}
+	var throttled *ThrottledError
+	if _, err := s.TwoFactor.StepUp(user.UserID, "sid", "00000-00000", client); !errors.As(err, &throttled) {
+		t.Fatalf("StepUp after %d failures err = %v, want ThrottledError", testThrottleConfig.AccountDelayAfter, err)
+	}
+	if err := s.TwoFactor.Disable(user.UserID, "00000-00000", model.ClientInfo{IP: "10.0.0.2"}); !errors.As(err, &throttled) {
+		t.Fatalf("Disable while throttled err = %v, want ThrottledError", err)
+	}
+}
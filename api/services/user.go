@@ -2,35 +2,73 @@ package services
 
 import (
 	"GoGin/api/dao"
+	"GoGin/internal/config"
+	"GoGin/internal/mailer"
 	"GoGin/internal/model"
 	"GoGin/internal/util"
+	"GoGin/internal/util/hasher"
 	"GoGin/internal/util/jwt_util"
+	"GoGin/internal/util/password_policy"
 	"errors"
+	"log"
 	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type UserService struct {
-	UserRepo dao.UserRepository
-	jwtUtil  jwt_util.Util
+	UserRepo       dao.UserRepository
+	SessionRepo    dao.SessionRepository
+	RevocationRepo dao.RevocationRepository
+	TokenRepo      dao.TokenRepository
+	InvitationRepo dao.InvitationRepository
+	GrantRepo      dao.RoleGrantRepository
+	TwoFactor      *TwoFactorService
+	Throttle       *LoginThrottle
+	mailer         mailer.Mailer
+	jwtUtil        jwt_util.Util
+	cfg            *config.Config
+	refreshTTL     time.Duration
+	passwordPolicy *password_policy.Policy
+	hasher         *hasher.Manager
+	// dummyHash 用户不存在时用于比对，使响应时间与密码错误一致
+	dummyHash string
 }
 
-func NewUserService(userRepo dao.UserRepository, jwtUtil jwt_util.Util) *UserService {
+func NewUserService(userRepo dao.UserRepository, sessionRepo dao.SessionRepository, revocationRepo dao.RevocationRepository, tokenRepo dao.TokenRepository, invitationRepo dao.InvitationRepository, grantRepo dao.RoleGrantRepository, twoFactor *TwoFactorService, throttle *LoginThrottle, mailer mailer.Mailer, jwtUtil jwt_util.Util, cfg *config.Config) *UserService {
+	passwordHasher, err := hasher.NewFromConfig(cfg.PasswordHash)
+	if err != nil {
+		log.Fatal("Failed to create password hasher:", err)
+	}
+	dummyHash, err := passwordHasher.Hash("dummy-password-for-timing")
+	if err != nil {
+		log.Fatal("Failed to create password hasher:", err)
+	}
+
 	return &UserService{
-		UserRepo: userRepo,
-		jwtUtil:  jwtUtil,
+		UserRepo:       userRepo,
+		SessionRepo:    sessionRepo,
+		RevocationRepo: revocationRepo,
+		TokenRepo:      tokenRepo,
+		InvitationRepo: invitationRepo,
+		GrantRepo:      grantRepo,
+		TwoFactor:      twoFactor,
+		Throttle:       throttle,
+		mailer:         mailer,
+		jwtUtil:        jwtUtil,
+		cfg:            cfg,
+		refreshTTL:     time.Duration(cfg.RefreshExpireHours) * time.Hour,
+		passwordPolicy: password_policy.NewPolicy(cfg.PasswordPolicy, cfg.PasswordHash.Algorithm),
+		hasher:         passwordHasher,
+		dummyHash:      dummyHash,
 	}
 }
 
 func (s *UserService) Register(req *model.RegisterRequest) (*model.User, error) {
-	//密码时候否符合格式
-	var flagPassword bool
-	for i := 0; i < len(req.Password); i++ {
-		if !((req.Password[i] >= 'a' && req.Password[i] <= 'z') || (req.Password[i] >= '0' && req.Password[i] <= '9') || (req.Password[i] >= 'A' && req.Password[i] <= 'Z')) {
-			flagPassword = true
-		}
-	}
-	if flagPassword {
-		return nil, errors.New("password format Error")
+	//密码是否符合策略
+	if err := s.passwordPolicy.Validate(req.Password); err != nil {
+		return nil, err
 	}
 
 	//邮箱是否符合格式
@@ -39,28 +77,70 @@ func (s *UserService) Register(req *model.RegisterRequest) (*model.User, error)
 	}
 
 	//加密密码
-	hashedPassword, err := util.HashPassword(req.Password)
+	hashedPassword, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		return nil, errors.New("password hash failed")
 	}
 
+	//角色：默认普通用户，特权角色只能通过邀请码获得
+	role := model.RoleUser
+	var invitation *model.Invitation
+	if req.InviteCode != "" {
+		invitation, err = s.InvitationRepo.UseInvitation(util.HashToken(req.InviteCode))
+		if err != nil {
+			return nil, err
+		}
+		role = invitation.Role
+	}
+
 	//创建用户
 	user := &model.User{
 		Username: req.Username,
 		Password: hashedPassword, // 加密存储
 		Email:    req.Email,
-		Role:     req.Role,
+		Role:     role,
 	}
 
 	//传入数据库
 	if err := s.UserRepo.AddUser(user); err != nil {
+		if invitation != nil {
+			_ = s.InvitationRepo.ReleaseInvitation(invitation.ID)
+		}
 		return nil, err
 	}
 
+	//记录授予
+	if invitation != nil {
+		if err := s.GrantRepo.RecordGrant(&model.RoleGrant{
+			UserID:       user.UserID,
+			Role:         role,
+			PreviousRole: "",
+			Source:       model.GrantSourceInvitation,
+			SourceID:     invitation.ID,
+			GrantedBy:    invitation.CreatedBy,
+		}); err != nil {
+			log.Println("record role grant failed:", err)
+		}
+	}
+
+	//发送验证邮件，发送失败时可以重新发送
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Println("send verification mail failed:", err)
+	}
+
 	return user, nil
 }
 
-func (s *UserService) Login(loginKey, password string) (string, *model.User, error, string) {
+// LoginResult 登录结果；开启双因素认证时只返回 ChallengeToken
+type LoginResult struct {
+	User           *model.User
+	Token          string
+	RefreshToken   string
+	MFARequired    bool
+	ChallengeToken string
+}
+
+func (s *UserService) Login(loginKey, password string, client model.ClientInfo) (*LoginResult, error) {
 	//判断是邮箱登录还是用户名登录
 	var user *model.User
 	var at, point bool
@@ -73,63 +153,292 @@ func (s *UserService) Login(loginKey, password string) (string, *model.User, err
 		}
 	}
 	if at && point { // 邮箱登录
-		userByEmail, err := s.UserRepo.SelectByEmail(loginKey)
-		if err != nil {
-			return "", nil, err, ""
+		if userByEmail, err := s.UserRepo.SelectByEmail(loginKey); err == nil {
+			user = userByEmail
 		}
-		user = userByEmail
 	} else { // 用户名登录
-		userByUsername, err := s.UserRepo.SelectByUsername(loginKey)
+		if userByUsername, err := s.UserRepo.SelectByUsername(loginKey); err == nil {
+			user = userByUsername
+		}
+	}
+
+	//限流
+	userID := 0
+	if user != nil {
+		userID = user.UserID
+	}
+	accountKey, ipKey := AccountKey(userID, loginKey), IPKey(client.IP)
+	if err := s.Throttle.Check(accountKey, ipKey); err != nil {
+		return nil, err
+	}
+
+	//检查用户是否存在，不存在时同样比对一次密码，避免通过响应时间判断账号是否存在
+	if user == nil || !s.UserRepo.Exists(user.Username, user.Email) {
+		s.hasher.Verify(s.dummyHash, password)
+		s.Throttle.Fail(accountKey, ipKey)
+		return nil, ErrInvalidCredentials
+	}
+
+	//检验密码正确性：缓存中的用户不含密码哈希，从数据库读取
+	user, err := s.UserRepo.SelectCredentials(user.UserID)
+	if err != nil {
+		s.hasher.Verify(s.dummyHash, password)
+		s.Throttle.Fail(accountKey, ipKey)
+		return nil, ErrInvalidCredentials
+	}
+	ok, rehash := s.hasher.Verify(user.Password, password)
+	if !ok {
+		s.Throttle.Fail(accountKey, ipKey)
+		return nil, ErrInvalidCredentials
+	}
+	s.Throttle.Succeed(accountKey)
+
+	//旧算法或旧参数的哈希在登录成功时升级
+	if rehash {
+		s.rehashPassword(user, password)
+	}
+
+	return s.finishLogin(user, []string{jwt_util.AMRPassword}, client)
+}
+
+// rehashPassword 升级失败不影响本次登录，下次登录会再次尝试
+func (s *UserService) rehashPassword(user *model.User, password string) {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Println("password rehash failed:", err)
+		return
+	}
+	if err := s.UserRepo.UpdatePassword(user.UserID, hashedPassword); err != nil {
+		log.Println("password rehash failed:", err)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+// finishLogin 第一步认证通过后检查邮箱验证状态；开启双因素认证时返回挑战令牌
+func (s *UserService) finishLogin(user *model.User, amr []string, client model.ClientInfo) (*LoginResult, error) {
+	//邮箱验证
+	if s.cfg.Verification.RequiredForLogin && !user.EmailVerified {
+		return nil, errors.New("email not verified")
+	}
+
+	//双因素认证
+	enabled, err := s.TwoFactor.IsEnabled(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		challenge, err := s.TwoFactor.NewChallenge(user, amr)
 		if err != nil {
-			return "", nil, err, ""
+			return nil, err
 		}
-		user = userByUsername
+		return &LoginResult{User: user, MFARequired: true, ChallengeToken: challenge}, nil
 	}
 
-	//检查用户是否存在
-	if flag := s.UserRepo.Exists(user.Username, user.Email); !flag {
-		return "", nil, errors.New("username Not Exist"), ""
+	return s.completeLogin(user, amr, client)
+}
+
+// LoginSecondFactor 校验登录挑战和验证码（TOTP 或恢复码）后签发令牌
+func (s *UserService) LoginSecondFactor(req model.LoginSecondFactorRequest, client model.ClientInfo) (*LoginResult, error) {
+	userID, amr, err := s.TwoFactor.VerifyChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
 	}
 
-	//检验密码正确性
-	if !util.CheckPassword(user.Password, password) {
-		return "", nil, errors.New("password error"), ""
+	//验证码同样计入失败次数
+	if err := s.TwoFactor.verifyCodeThrottled(userID, req.Code, client); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepo.SelectByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.completeLogin(user, append(amr, jwt_util.AMROTP), client)
+}
+
+// completeLogin 创建刷新会话并签发绑定该会话的访问令牌
+func (s *UserService) completeLogin(user *model.User, amr []string, client model.ClientInfo) (*LoginResult, error) {
+	//停用的账号拒绝登录
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
 	}
 
 	//鉴权
 	role, err := s.UserRepo.GetRole(user)
 	if err != nil {
-		return "", nil, errors.New("get role error"), ""
+		return nil, errors.New("get role error")
 	}
 
-	//access token
-	token, err := s.jwtUtil.GenerateToken(user.UserID, user.Username, role, 1)
 	//refresh token
-	refreshToken, err := s.jwtUtil.GenerateToken(user.UserID, user.Username, role, 255)
+	sessionID, refreshToken, err := s.newSession(user.UserID, client)
+	if err != nil {
+		return nil, err
+	}
+	//access token
+	claims := newAccessClaims(user, amr)
+	claims.Role = role
+	claims.SessionID = sessionID
+	token, err := s.jwtUtil.GenerateToken(claims)
 	if err != nil {
-		return "", nil, errors.New("token Error"), ""
+		return nil, errors.New("token Error")
 	}
 
-	return token, user, nil, refreshToken
+	return &LoginResult{User: user, Token: token, RefreshToken: refreshToken}, nil
 }
 
-func (s *UserService) Refresh(refreshToken model.RefreshTokenRequest) (string, error) {
-	//验证token
-	token, err := s.jwtUtil.ValidateToken(refreshToken.RefreshToken)
+// Refresh 轮换刷新令牌，旧令牌被重复使用时吊销整个会话
+func (s *UserService) Refresh(req model.RefreshTokenRequest, client model.ClientInfo) (string, string, error) {
+	//解析令牌
+	sessionID, ok := parseRefreshToken(req.RefreshToken)
+	if !ok {
+		return "", "", errors.New("refresh Token Error")
+	}
+
+	session, err := s.SessionRepo.GetSession(sessionID)
+	if err != nil {
+		return "", "", errors.New("refresh Token Error")
+	}
+	if session.Revoked {
+		return "", "", errors.New("session revoked")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return "", "", errors.New("session expired")
+	}
+
+	//重用检测：令牌属于该会话但已被轮换过
+	oldHash := util.HashToken(req.RefreshToken)
+	if session.TokenHash != oldHash {
+		_ = s.revokeSession(sessionID)
+		return "", "", errors.New("refresh token reused, session revoked")
+	}
+
+	//轮换
+	secret, err := util.RandomToken(32)
 	if err != nil {
-		return "", errors.New("refresh Token Error")
+		return "", "", errors.New("token generate failed")
+	}
+	newRefreshToken := sessionID + "." + secret
+	if err := s.SessionRepo.RotateSession(sessionID, oldHash, util.HashToken(newRefreshToken), time.Now().Add(s.refreshTTL)); err != nil {
+		// 并发轮换同样视为重用
+		_ = s.revokeSession(sessionID)
+		return "", "", errors.New("refresh token reused, session revoked")
 	}
 
-	//提取声明
-	claims, err := s.jwtUtil.ExtractClaims(token)
+	user, err := s.UserRepo.SelectByID(session.UserID)
 	if err != nil {
-		return "", errors.New("extract claims failed")
+		return "", "", errors.New("user select failed")
+	}
+	if user.DisabledAt != nil {
+		_ = s.revokeSession(sessionID)
+		return "", "", ErrAccountDisabled
 	}
 
-	newToken, err := s.jwtUtil.GenerateToken(claims["user_id"].(int), claims["username"].(string), claims["role"].(string), 1)
+	claims := newAccessClaims(user, nil)
+	claims.SessionID = sessionID
+	newToken, err := s.jwtUtil.GenerateToken(claims)
 	if err != nil {
-		return "", errors.New("token generate failed")
+		return "", "", errors.New("token generate failed")
+	}
+	s.TouchSession(sessionID, client)
+
+	return newToken, newRefreshToken, nil
+}
+
+// Logout 吊销刷新令牌所在的会话，并将当前访问令牌加入黑名单
+func (s *UserService) Logout(req model.LogoutRequest, jti string, tokenExp time.Time) error {
+	sessionID, ok := parseRefreshToken(req.RefreshToken)
+	if !ok {
+		return errors.New("refresh Token Error")
+	}
+
+	// 已轮换过的旧令牌同样吊销整个会话
+	if _, err := s.SessionRepo.GetSession(sessionID); err != nil {
+		return errors.New("refresh Token Error")
+	}
+	if err := s.revokeSession(sessionID); err != nil {
+		return err
 	}
 
-	return newToken, nil
+	if jti != "" {
+		if err := s.RevocationRepo.RevokeToken(jti, tokenExp); err != nil {
+			return errors.New("access token revoke failed")
+		}
+	}
+	return nil
+}
+
+// UnlockAccount 管理员解除账号的登录限制
+func (s *UserService) UnlockAccount(userID int) error {
+	if _, err := s.UserRepo.SelectByID(userID); err != nil {
+		return err
+	}
+	return s.Throttle.Unlock(userID)
+}
+
+// InvalidateUserTokens 吊销用户全部会话，并使此前签发的访问令牌失效
+// 用于修改密码、降级角色、封禁账号等场景
+func (s *UserService) InvalidateUserTokens(userID int) error {
+	if err := s.SessionRepo.RevokeUserSessions(userID); err != nil {
+		return err
+	}
+	if err := s.RevocationRepo.RevokeUserTokensBefore(userID, time.Now()); err != nil {
+		return errors.New("access token revoke failed")
+	}
+	return nil
+}
+
+// newSession 创建会话，返回会话ID和刷新令牌 "<session_id>.<secret>"
+func (s *UserService) newSession(userID int, client model.ClientInfo) (string, string, error) {
+	sessionID, err := util.RandomID(16)
+	if err != nil {
+		return "", "", errors.New("token Error")
+	}
+	secret, err := util.RandomToken(32)
+	if err != nil {
+		return "", "", errors.New("token Error")
+	}
+	refreshToken := sessionID + "." + secret
+
+	now := time.Now()
+	client = normalizeClient(client)
+	session := &model.Session{
+		SessionID:  sessionID,
+		UserID:     userID,
+		TokenHash:  util.HashToken(refreshToken),
+		ExpiresAt:  now.Add(s.refreshTTL),
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastSeenAt: now,
+	}
+	if err := s.SessionRepo.CreateSession(session); err != nil {
+		return "", "", err
+	}
+
+	return sessionID, refreshToken, nil
+}
+
+// newAccessClaims amr 记录本次认证使用的方式，包含 otp 时同时记录认证时间
+func newAccessClaims(user *model.User, amr []string) *jwt_util.Claims {
+	claims := &jwt_util.Claims{
+		UserID:        user.UserID,
+		Username:      user.Username,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		AMR:           amr,
+	}
+	for _, method := range amr {
+		if method == jwt_util.AMROTP {
+			claims.AuthTime = jwt.NewNumericDate(time.Now())
+		}
+	}
+	return claims
+}
+
+func parseRefreshToken(refreshToken string) (string, bool) {
+	sessionID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", false
+	}
+	return sessionID, true
 }
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"GoGin/api/dao"
+	"GoGin/api/dao/cache"
+	"GoGin/api/dao/memory"
+	"GoGin/internal/config"
+	"GoGin/internal/mailer"
+	"GoGin/internal/model"
+	"GoGin/internal/util/jwt_util"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestConfig() *config.Config {
+	return &config.Config{
+		JWTSecret:          "test-secret",
+		JWTIssuer:          "test",
+		JWTExpireHours:     1,
+		RefreshExpireHours: 24,
+		PasswordHash:       config.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 4},
+	}
+}
+
+// newTestUserService 内存存储 + 进程内缓存
+func newTestUserService(cfg *config.Config, cacheClient cache.Cache) *UserService {
+	userRepo := memory.NewMemoryUserRepo()
+	jwtUtil := jwt_util.NewJWTUtil(cfg)
+	loginThrottle := NewLoginThrottle(cache.NewCacheLoginAttemptRepo(cacheClient), cfg)
+	twoFactorService := NewTwoFactorService(memory.NewMemoryTwoFactorRepo(), userRepo, loginThrottle, jwtUtil, cfg)
+	revocationRepo := cache.NewCacheRevocationRepo(cacheClient, time.Hour, false)
+	return NewUserService(userRepo, memory.NewMemorySessionRepo(), revocationRepo, memory.NewMemoryTokenRepo(),
+		memory.NewMemoryInvitationRepo(), memory.NewMemoryRoleGrantRepo(), twoFactorService, loginThrottle, mailer.NewFileMailer(""), jwtUtil, cfg)
+}
+
+func TestLoginAndRefresh(t *testing.T) {
+	s := newTestUserService(newTestConfig(), cache.NewMemoryCache(1000))
+	if _, err := s.Register(&model.RegisterRequest{Username: "alice", Password: "correcthorse42", Email: "alice@school.edu"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// 连续登录都要能读到密码哈希
+	var login *LoginResult
+	for i := 0; i < 2; i++ {
+		result, err := s.Login("alice", "correcthorse42", model.ClientInfo{IP: "127.0.0.1"})
+		if err != nil {
+			t.Fatalf("Login #%d: %v", i+1, err)
+		}
+		login = result
+	}
+	if _, err := s.Login("alice", "wrong password", model.ClientInfo{IP: "127.0.0.1"}); err != ErrInvalidCredentials {
+		t.Fatalf("Login with wrong password: err = %v, want ErrInvalidCredentials", err)
+	}
+
+	// 刷新令牌轮换：新令牌可以继续刷新，旧令牌被重复使用时拒绝
+	refreshToken := login.RefreshToken
+	for i := 0; i < 2; i++ {
+		_, next, err := s.Refresh(model.RefreshTokenRequest{RefreshToken: refreshToken}, model.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Refresh #%d: %v", i+1, err)
+		}
+		refreshToken = next
+	}
+	if _, _, err := s.Refresh(model.RefreshTokenRequest{RefreshToken: login.RefreshToken}, model.ClientInfo{}); err == nil {
+		t.Fatal("Refresh accepted a reused refresh token")
+	}
+}
+
+// failingTwoFactorRepo 模拟存储故障
+type failingTwoFactorRepo struct {
+	dao.TwoFactorRepository
+}
+
+func (failingTwoFactorRepo) GetTwoFactor(int) (*model.TwoFactor, error) {
+	return nil, errors.New("connection refused")
+}
+
+// 查询双因素配置失败时不能跳过第二步直接签发令牌
+func TestLoginFailsClosedWhenTwoFactorLookupFails(t *testing.T) {
+	s := newTestUserService(newTestConfig(), cache.NewMemoryCache(1000))
+	if _, err := s.Register(&model.RegisterRequest{Username: "alice", Password: "correcthorse42", Email: "alice@school.edu"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	s.TwoFactor.TwoFactorRepo = failingTwoFactorRepo{}
+
+	result, err := s.Login("alice", "correcthorse42", model.ClientInfo{IP: "127.0.0.1"})
+	if err == nil {
+		t.Fatalf("Login succeeded with result %+v, want error", result)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"GoGin/api/dao/cache"
+	"GoGin/internal/config"
+	"log"
+	"time"
+)
+
+// newCache 按 CACHE_DRIVER 创建缓存，返回 nil 表示不使用缓存，各仓库会直接访问存储
+func newCache(cfg *config.Config) cache.Cache {
+	switch cfg.Cache.Driver {
+	case "redis", "":
+		if cfg.Redis.Addr == "" {
+			log.Println("Redis配置为空，跳过缓存初始化")
+			return nil
+		}
+		return cache.NewRedisClient(
+			cfg.Redis.Addr,
+			cfg.Redis.Password,
+			cfg.Redis.DB,
+		)
+	case "layered":
+		if cfg.Redis.Addr == "" {
+			log.Fatal("CACHE_DRIVER=layered requires REDIS_ADDR")
+		}
+		redisClient := cache.NewRedisClient(
+			cfg.Redis.Addr,
+			cfg.Redis.Password,
+			cfg.Redis.DB,
+		)
+		return cache.NewLayeredCache(redisClient.(*cache.RedisClient), time.Duration(cfg.Cache.L1TTLSeconds)*time.Second, cfg.Cache.L1MaxEntries)
+	case "memory":
+		log.Println("使用进程内缓存，锁只在本实例内有效")
+		return cache.NewMemoryCache(cfg.Cache.MaxEntries)
+	case "none":
+		log.Println("未启用缓存")
+		return nil
+	default:
+		log.Fatalf("unknown CACHE_DRIVER %q", cfg.Cache.Driver)
+		return nil
+	}
+}
+
+// newStateCache 令牌吊销、登录失败计数和 OIDC state 单独保存，不与仓库数据缓存共用，避免被普通缓存条目淘汰。
+// 配置了 Redis 时直接使用 Redis（不经过本地 L1，吊销立即对所有实例生效），Redis 应使用 noeviction 或 volatile-* 淘汰策略；
+// 否则保存在不按容量淘汰的进程内存储中，只在本实例内有效，进程重启后丢失
+func newStateCache(cfg *config.Config) cache.Cache {
+	switch cfg.Cache.Driver {
+	case "redis", "", "layered":
+		if cfg.Redis.Addr != "" {
+			return cache.NewRedisClient(
+				cfg.Redis.Addr,
+				cfg.Redis.Password,
+				cfg.Redis.DB,
+			)
+		}
+	}
+	log.Println("未使用Redis，令牌吊销等状态保存在进程内，只在本实例内有效")
+	return cache.NewStateMemoryCache()
+}
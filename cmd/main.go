@@ -2,13 +2,17 @@ package main
 
 import (
 	"GoGin/api/dao/cache"
-	"GoGin/api/dao/mysql"
 	handlers2 "GoGin/api/handlers"
 	"GoGin/api/services"
+	"GoGin/internal/audit"
 	"GoGin/internal/config"
+	"GoGin/internal/mailer"
 	"GoGin/internal/middleware"
+	"GoGin/internal/model"
+	"GoGin/internal/oidc"
 	"GoGin/internal/util/jwt_util"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,55 +22,154 @@ func main() {
 	//======================================初始化====================================================
 	// 数据层依赖
 
-	// userRepo := memory.NewMemoryUserRepository() 内存记忆储存信息
+	// 缓存：Redis / 进程内 / 不使用
+	cacheClient := newCache(cfg)
 
-	// MySQL
-	db, err := mysql.InitMysql(cfg)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Redis
-	var redisClient cache.Cache
-	if cfg.Redis.Addr != "" {
-		redisClient = cache.NewRedisClient(
-			cfg.Redis.Addr,
-			cfg.Redis.Password,
-			cfg.Redis.DB,
-		)
-	} else {
-		log.Println("Redis配置为空，跳过缓存初始化")
-	}
-
-	// dao
-	userRepo := mysql.NewMysqlUserRepo(db, redisClient.(*cache.RedisClient))
-	courseRepo := mysql.NewMysqlCourseRepo(db, redisClient.(*cache.RedisClient))
-	todoRepo := mysql.NewMysqlTodoRepo(db, redisClient.(*cache.RedisClient))
+	// dao：STORAGE_DRIVER=memory 时不连接 MySQL
+	repos := newRepositories(cfg, cacheClient)
+	userRepo := repos.user
+	courseRepo := repos.course
+	todoRepo := repos.todo
+	sessionRepo := repos.session
+	tokenRepo := repos.token
+	twoFactorRepo := repos.twoFactor
+	roleRepo := repos.role
+	invitationRepo := repos.invitation
+	grantRepo := repos.grant
+	apiKeyRepo := repos.apiKey
+	identityRepo := repos.identity
+	auditRepo := repos.audit
+	// 令牌吊销等状态：独立于数据缓存，未使用 Redis 时保存在进程内
+	stateCache := newStateCache(cfg)
+	oidcStateRepo := cache.NewCacheOIDCStateRepo(stateCache)
+	loginAttemptRepo := cache.NewCacheLoginAttemptRepo(stateCache)
+	revocationRepo := cache.NewCacheRevocationRepo(stateCache, time.Duration(cfg.JWTExpireHours)*time.Hour, cfg.RevocationFailOpen)
 	// JWT工具
 	jwtUtil := jwt_util.NewJWTUtil(cfg)
+	// 邮件
+	mailSender := mailer.NewMailer(cfg)
+	// 审计日志：数据库 + 可选的 JSON Lines 文件
+	auditLoggers := []audit.AuditLogger{audit.NewStoreLogger(auditRepo)}
+	if cfg.AuditLogFile != "" {
+		fileSink, err := audit.NewFileSink(cfg.AuditLogFile)
+		if err != nil {
+			log.Fatal("Failed to open audit log file:", err)
+		}
+		auditLoggers = append(auditLoggers, fileSink)
+	}
+	auditLogger := audit.NewMultiLogger(auditLoggers...)
+	// 外部身份提供方
+	oidcProviders := oidc.NewRegistry(cfg.OIDCProviders, nil)
 	// 业务逻辑层依赖
-	userService := services.NewUserService(userRepo, jwtUtil)
+	loginThrottle := services.NewLoginThrottle(loginAttemptRepo, cfg)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, userRepo, loginThrottle, jwtUtil, cfg)
+	userService := services.NewUserService(userRepo, sessionRepo, revocationRepo, tokenRepo, invitationRepo, grantRepo, twoFactorService, loginThrottle, mailSender, jwtUtil, cfg)
+	roleService := services.NewRoleService(roleRepo, userRepo, grantRepo, userService)
+	invitationService := services.NewInvitationService(invitationRepo, roleRepo, roleService)
+	accountService := services.NewAccountService(userRepo, courseRepo, todoRepo, tokenRepo, sessionRepo, apiKeyRepo, twoFactorRepo, identityRepo, userService, mailSender, cfg)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, roleRepo)
+	oidcService := services.NewOIDCService(oidcProviders, identityRepo, oidcStateRepo, userRepo, userService, cfg)
+	auditService := services.NewAuditService(auditRepo)
+	impersonationService := services.NewImpersonationService(userRepo, roleService, jwtUtil, cfg)
 	courseService := services.NewCourseService(courseRepo)
 	todoService := services.NewTodoService(todoRepo)
 	// 处理器层依赖
 	userHandler := handlers2.NewUserHandler(userService)
 	courseHandler := handlers2.NewCourseHandler(courseService)
 	todoHandler := handlers2.NewTodoHandler(todoService)
+	jwksHandler := handlers2.NewJWKSHandler(jwtUtil)
+	twoFactorHandler := handlers2.NewTwoFactorHandler(twoFactorService)
+	roleHandler := handlers2.NewRoleHandler(roleService)
+	invitationHandler := handlers2.NewInvitationHandler(invitationService)
+	adminUserHandler := handlers2.NewAdminUserHandler(userService)
+	accountHandler := handlers2.NewAccountHandler(accountService)
+	apiKeyHandler := handlers2.NewAPIKeyHandler(apiKeyService)
+	oidcHandler := handlers2.NewOIDCHandler(oidcService)
+	auditHandler := handlers2.NewAuditHandler(auditService)
+	impersonationHandler := handlers2.NewImpersonationHandler(impersonationService)
 	//创建中间件
-	jwtMiddleware := middleware.NewJWTMiddleware(jwtUtil)
+	jwtMiddleware := middleware.NewJWTMiddleware(jwtUtil, revocationRepo, roleService, apiKeyService, userService, cfg)
+	auditMiddleware := middleware.NewAuditMiddleware(auditLogger)
+
+	// 模拟登录的令牌不能执行的敏感操作
+	noImpersonation := jwtMiddleware.BlockImpersonation()
+
+	stepUpMaxAge := time.Duration(cfg.StepUpMaxAgeMinutes) * time.Minute
+
+	// 还没有管理员时输出一次性的管理员邀请码，注册时填写即可成为管理员
+	bootstrapCode, err := invitationService.BootstrapAdminInvitation(24 * time.Hour)
+	if err != nil {
+		log.Fatal("Failed to check initial admin:", err)
+	}
+	if bootstrapCode != "" {
+		log.Println("系统中还没有管理员，使用以下邀请码注册第一个管理员（24小时内有效，仅可使用一次）:", bootstrapCode)
+	}
+
+	// 定期彻底删除超过宽限期的注销账号
+	go accountService.RunPurger(time.Duration(cfg.AccountPurgeIntervalMinutes) * time.Minute)
 
 	r := gin.Default()
+	r.Use(middleware.RequestID(), auditMiddleware.RecordImpersonation())
+
+	// 验签公钥
+	r.GET("/.well-known/jwks.json", jwksHandler.JWKS)
 
 	//=======================================注册和登录路由=============================================
 	user := r.Group("/user")
-	user.POST("/register", userHandler.Register)
-	user.POST("/login", userHandler.Login)
-	user.POST("/refresh", userHandler.Refresh)
-	user.GET("/info", jwtMiddleware.JWTAuthentication(), userHandler.InfoHandler)
+	user.POST("/register", auditMiddleware.Record(model.AuditActionRegister), userHandler.Register)
+	user.POST("/login", auditMiddleware.Record(model.AuditActionLogin), userHandler.Login)
+	user.POST("/login/2fa", auditMiddleware.Record(model.AuditActionLoginOTP), userHandler.LoginSecondFactor)
+	user.POST("/refresh", auditMiddleware.Record(model.AuditActionRefresh), userHandler.Refresh)
+	user.POST("/logout", auditMiddleware.Record(model.AuditActionLogout), jwtMiddleware.JWTAuthentication(), userHandler.Logout)
+	user.GET("/info", jwtMiddleware.Authenticate(), userHandler.InfoHandler)
+	user.POST("/password/forgot", userHandler.ForgotPassword)
+	user.POST("/password/reset", auditMiddleware.Record(model.AuditActionPasswordReset), userHandler.ResetPassword)
+	user.GET("/verify", userHandler.VerifyEmail)
+	user.POST("/verify/resend", jwtMiddleware.JWTAuthentication(), noImpersonation, userHandler.ResendVerification)
+
+	//个人信息修改
+	user.PUT("/password", auditMiddleware.Record(model.AuditActionPasswordChange), jwtMiddleware.JWTAuthentication(), noImpersonation, userHandler.ChangePassword)
+	user.PUT("/username", jwtMiddleware.JWTAuthentication(), noImpersonation, userHandler.ChangeUsername)
+	user.PUT("/email", auditMiddleware.Record(model.AuditActionEmailChange), jwtMiddleware.JWTAuthentication(), noImpersonation, userHandler.ChangeEmail)
+	user.GET("/email/confirm", userHandler.ConfirmEmailChange)
+
+	//注销与恢复
+	user.DELETE("/account", auditMiddleware.Record(model.AuditActionAccountDelete), jwtMiddleware.JWTAuthentication(), noImpersonation, accountHandler.Delete)
+	user.GET("/account/restore", accountHandler.Restore)
+
+	//外部身份登录与绑定
+	user.GET("/oidc/providers", oidcHandler.Providers)
+	user.GET("/oidc/:provider/login", oidcHandler.Login)
+	user.GET("/oidc/:provider/callback", auditMiddleware.Record(model.AuditActionLoginOIDC), oidcHandler.Callback)
+	user.POST("/oidc/:provider/link", jwtMiddleware.JWTAuthentication(), noImpersonation, oidcHandler.Link)
+	user.GET("/identities", jwtMiddleware.JWTAuthentication(), oidcHandler.ListIdentities)
+	user.DELETE("/identities/:id", jwtMiddleware.JWTAuthentication(), noImpersonation, oidcHandler.Unlink)
+
+	//登录设备
+	user.GET("/sessions", jwtMiddleware.JWTAuthentication(), userHandler.ListSessions)
+	user.DELETE("/sessions/:id", auditMiddleware.Record(model.AuditActionSessionRevoke), jwtMiddleware.JWTAuthentication(), noImpersonation, userHandler.RevokeSession)
+
+	//API Key
+	user.POST("/api-keys", auditMiddleware.Record(model.AuditActionAPIKeyCreate), jwtMiddleware.JWTAuthentication(), noImpersonation, apiKeyHandler.Create)
+	user.GET("/api-keys", jwtMiddleware.JWTAuthentication(), apiKeyHandler.List)
+	user.DELETE("/api-keys/:id", auditMiddleware.Record(model.AuditActionAPIKeyRevoke), jwtMiddleware.JWTAuthentication(), noImpersonation, apiKeyHandler.Revoke)
+
+	//双因素认证
+	twoFactor := user.Group("/2fa")
+	twoFactor.Use(jwtMiddleware.JWTAuthentication(), noImpersonation)
+	twoFactor.POST("/setup", twoFactorHandler.Setup)
+	twoFactor.POST("/confirm", auditMiddleware.Record(model.AuditActionTwoFactor), twoFactorHandler.Confirm)
+	twoFactor.POST("/disable", auditMiddleware.Record(model.AuditActionTwoFactor), twoFactorHandler.Disable)
+	twoFactor.POST("/recovery-codes", auditMiddleware.Record(model.AuditActionTwoFactor), twoFactorHandler.RecoveryCodes)
+	twoFactor.POST("/step-up", twoFactorHandler.StepUp)
+
+	//角色申请
+	user.POST("/role-requests", jwtMiddleware.JWTAuthentication(), noImpersonation, invitationHandler.RequestRole)
+	user.GET("/role-requests", jwtMiddleware.JWTAuthentication(), invitationHandler.MyRequests)
 
 	//========================================课程相关路由==============================================
 	course := r.Group("/course")
-	course.Use(jwtMiddleware.JWTAuthentication())
+	course.Use(jwtMiddleware.Authenticate(), jwtMiddleware.RequireScope(model.ScopeCourse), jwtMiddleware.RequireVerifiedEmail("course"))
 	//获取课程列表
 	course.GET("/info", courseHandler.Info)
 	//获取已选课程列表
@@ -75,12 +178,12 @@ func main() {
 	course.POST("/pick", courseHandler.PickCourse)
 	//退课
 	course.POST("/drop", courseHandler.DropCourse)
-	//新增课程 (admin)
-	course.POST("/add/course", jwtMiddleware.JWTAuthentication(), jwtMiddleware.JWTAuthorization(), courseHandler.AddCourse)
+	//新增课程 (course:create)
+	course.POST("/add/course", auditMiddleware.Record(model.AuditActionCourseAdd), jwtMiddleware.Require(model.PermCourseCreate), noImpersonation, jwtMiddleware.RequireRecentOTP(stepUpMaxAge), courseHandler.AddCourse)
 
 	//=======================================to-do-list相关路由==========================================
 	todo := r.Group("/to-do")
-	todo.Use(jwtMiddleware.JWTAuthentication())
+	todo.Use(jwtMiddleware.Authenticate(), jwtMiddleware.RequireScope(model.ScopeTodo), jwtMiddleware.RequireVerifiedEmail("to-do"))
 	//新增to-do事项
 	todo.POST("/create", todoHandler.Create)
 	//完成to-do事项
@@ -90,6 +193,39 @@ func main() {
 	//获取to-do事项列表
 	todo.GET("/info", todoHandler.Info)
 
+	//=======================================管理员相关路由==============================================
+	admin := r.Group("/admin")
+	admin.Use(jwtMiddleware.Authenticate(), noImpersonation, jwtMiddleware.JWTAuthorization())
+	//角色与权限
+	admin.GET("/roles", jwtMiddleware.Require(model.PermRoleManage), roleHandler.ListRoles)
+	admin.POST("/roles", auditMiddleware.Record(model.AuditActionRoleCreate), jwtMiddleware.Require(model.PermRoleManage), roleHandler.CreateRole)
+	admin.PUT("/roles/:name/permissions", auditMiddleware.Record(model.AuditActionRolePermission), jwtMiddleware.Require(model.PermRoleManage), roleHandler.SetPermissions)
+	admin.GET("/permissions", jwtMiddleware.Require(model.PermRoleManage), roleHandler.ListPermissions)
+	//用户管理
+	admin.GET("/users", jwtMiddleware.Require(model.PermUserManage), adminUserHandler.List)
+	admin.GET("/users/:id", jwtMiddleware.Require(model.PermUserManage), adminUserHandler.Get)
+	admin.POST("/users/:id/logout", auditMiddleware.Record(model.AuditActionUserLogout), jwtMiddleware.Require(model.PermUserManage), adminUserHandler.ForceLogout)
+	//分配角色
+	admin.PUT("/users/:id/role", auditMiddleware.Record(model.AuditActionRoleAssign), jwtMiddleware.Require(model.PermRoleManage), roleHandler.AssignRole)
+	admin.GET("/role-grants", jwtMiddleware.Require(model.PermRoleManage), roleHandler.ListGrants)
+	//模拟登录
+	admin.POST("/users/:id/impersonate", auditMiddleware.Record(model.AuditActionImpersonate), jwtMiddleware.Require(model.PermUserManage), impersonationHandler.Impersonate)
+	//解除登录锁定
+	admin.POST("/users/:id/unlock", auditMiddleware.Record(model.AuditActionUserUnlock), jwtMiddleware.Require(model.PermUserManage), adminUserHandler.Unlock)
+	//停用与启用账号
+	admin.POST("/users/:id/disable", auditMiddleware.Record(model.AuditActionUserDisable), jwtMiddleware.Require(model.PermUserManage), accountHandler.Disable)
+	admin.POST("/users/:id/enable", auditMiddleware.Record(model.AuditActionUserEnable), jwtMiddleware.Require(model.PermUserManage), accountHandler.Enable)
+	//邀请码
+	admin.POST("/invitations", auditMiddleware.Record(model.AuditActionInviteCreate), jwtMiddleware.Require(model.PermRoleManage), invitationHandler.CreateInvitation)
+	admin.GET("/invitations", jwtMiddleware.Require(model.PermRoleManage), invitationHandler.ListInvitations)
+	admin.DELETE("/invitations/:id", auditMiddleware.Record(model.AuditActionInviteRevoke), jwtMiddleware.Require(model.PermRoleManage), invitationHandler.RevokeInvitation)
+	//审计日志
+	admin.GET("/audit", jwtMiddleware.Require(model.PermAuditRead), auditHandler.List)
+	//角色申请审批
+	admin.GET("/role-requests", jwtMiddleware.Require(model.PermRoleManage), invitationHandler.ListRequests)
+	admin.POST("/role-requests/:id/approve", auditMiddleware.Record(model.AuditActionRoleApprove), jwtMiddleware.Require(model.PermRoleManage), invitationHandler.Approve)
+	admin.POST("/role-requests/:id/reject", auditMiddleware.Record(model.AuditActionRoleReject), jwtMiddleware.Require(model.PermRoleManage), invitationHandler.Reject)
+
 	err = r.Run()
 	if err != nil {
 		panic("Failed to start Gin server: " + err.Error())
@@ -105,21 +241,160 @@ func main() {
 		username
 		password
 		email
-		role (admin/user)
+		invite_code (可选，管理员签发，注册即获得对应角色；否则为普通用户)
 
 "/login":
 	Body:
 		login_key
 		password
+	失败过多时返回 429 与 Retry-After
+
+"/login/2fa":
+	Body:
+		challenge_token
+		code (TOTP 或恢复码)
 
 "/refresh":
 	Body:
 		refresh_token
 
+"/logout":
+	Header:
+		Authorization : Bearer <Token>
+	Body:
+		refresh_token
+
 "/info":
 	Header:
 		Authorization : Bearer <Token>
 
+"/password/forgot":
+	Body:
+		email
+
+"/password/reset":
+	Body:
+		token
+		new_password
+
+"/verify":
+	Query:
+		token
+
+"/verify/resend":
+	Header:
+		Authorization : Bearer <Token>
+
+"/password" (PUT):
+	Header:
+		Authorization : Bearer <Token>
+	Body:
+		current_password
+		new_password
+
+"/username" (PUT):
+	Header:
+		Authorization : Bearer <Token>
+	Body:
+		username
+
+"/email" (PUT):
+	Header:
+		Authorization : Bearer <Token>
+	Body:
+		password
+		new_email
+
+"/email/confirm":
+	Query:
+		token
+
+"/account" (DELETE):
+	Header:
+		Authorization : Bearer <Token>
+	Body:
+		password
+
+"/account/restore":
+	Query:
+		token
+
+"/oidc/providers":
+	nil
+
+"/oidc/:provider/login":
+	nil (返回 authorization_url，跳转后由提供方回调)
+
+"/oidc/:provider/callback":
+	Query:
+		code
+		state
+	(返回与 "/login" 相同)
+
+"/oidc/:provider/link" (POST):
+	Header:
+		Authorization : Bearer <Token>
+	(返回 authorization_url，回调后完成绑定)
+
+"/identities" (GET):
+	Header:
+		Authorization : Bearer <Token>
+
+"/identities/:id" (DELETE):
+	Header:
+		Authorization : Bearer <Token>
+
+"/sessions" (GET):
+	Header:
+		Authorization : Bearer <Token>
+	返回未过期的登录会话（设备、IP、创建与最近活跃时间），current 标记当前会话
+
+"/sessions/:id" (DELETE):
+	Header:
+		Authorization : Bearer <Token>
+	吊销该会话，其刷新令牌和访问令牌立即失效
+
+"/api-keys" (POST):
+	Header:
+		Authorization : Bearer <Token>
+	Body:
+		name
+		scopes (course / to-do / 当前角色拥有的权限，如 admin:access)
+		expires_in_days (1-365)
+
+"/api-keys" (GET):
+	Header:
+		Authorization : Bearer <Token>
+
+"/api-keys/:id" (DELETE):
+	Header:
+		Authorization : Bearer <Token>
+
+/course、/to-do、/admin 以及 /user/info 也可以使用 API Key：
+	Header:
+		Authorization : Bearer cdk_... 或 X-API-Key : cdk_...
+
+"/2fa/setup":
+	Header:
+		Authorization : Bearer <Token>
+
+"/2fa/confirm" "/2fa/disable" "/2fa/recovery-codes" "/2fa/step-up":
+	Header:
+		Authorization : Bearer <Token>
+	Body:
+		code
+
+"/role-requests" (POST):
+	Header:
+		Authorization : Bearer <Token>
+	Body:
+		role
+		reason
+
+"/role-requests" (GET):
+	Header:
+		Authorization : Bearer <Token>
+
 ===================="/course"=====================
 "/pick"
 	Header:
@@ -135,7 +410,7 @@ func main() {
 
 "/add/course":
 	Header:
-		Authorization : Bearer <Token>
+		Authorization : Bearer <Token> (需通过 /user/2fa/step-up 获取)
 	Body:
 		name
 		capital
@@ -166,4 +441,89 @@ func main() {
 		Authorization : Bearer <Token>
 	Body:
 		todo_id
+
+==================="/admin"======================
+所有接口:
+	Header:
+		Authorization : Bearer <Token> (admin:access)
+
+"/roles" (GET):
+	nil
+
+"/roles" (POST):
+	Body:
+		name
+		description
+		permissions
+
+"/roles/:name/permissions" (PUT):
+	Body:
+		permissions
+
+"/permissions" (GET):
+	nil
+
+"/users" (GET):
+	Query:
+		role (可选)
+		email_domain (可选，如 example.com)
+		created_after created_before (可选，2006-01-02)
+		cursor (可选，上一页返回的 next_cursor)
+		limit (可选，默认20，最大100)
+
+"/users/:id" (GET):
+	nil
+
+"/users/:id/logout" (POST):
+	nil
+
+"/users/:id/role" (PUT):
+	Body:
+		role
+
+"/users/:id/unlock" (POST):
+	nil
+
+"/users/:id/disable" "/users/:id/enable" (POST):
+	nil
+
+"/role-grants" (GET):
+	Query:
+		user_id (可选)
+
+"/invitations" (POST):
+	Body:
+		role
+		max_uses
+		expires_in_hours
+
+"/invitations" (GET):
+	nil
+
+"/invitations/:id" (DELETE):
+	nil
+
+"/role-requests" (GET):
+	Query:
+		status (可选: pending/approved/rejected)
+
+"/role-requests/:id/approve" "/role-requests/:id/reject" (POST):
+	Body:
+		note (可选)
+
+"/users/:id/impersonate" (POST): (user:manage)
+	以目标用户身份签发短期访问令牌（IMPERSONATION_TTL_MINUTES），令牌带 act 声明，没有刷新令牌
+	不能模拟管理员；模拟期间不能修改密码/邮箱/用户名、注销账号、管理 API Key、双因素认证等，也不能访问 /admin
+	模拟期间的所有请求都会写入审计日志，/user/info 返回 impersonated 和 impersonator
+
+"/audit" (GET): (audit:read)
+	Query:
+		actor_id (可选)
+		action (可选，如 user.login / course.add)
+		from, to (可选，RFC 3339 时间，如 2026-01-02T15:04:05Z)
+		cursor (上一页返回的 next_cursor)
+		limit (默认50，最大100)
+	登录、刷新、注册、新增课程及管理操作均会记录 操作者/动作/对象/IP/请求ID/结果
+	每个响应都带有 X-Request-ID 头，请求中携带时沿用
+
 */
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"GoGin/api/dao"
+	"GoGin/api/dao/cache"
+	"GoGin/api/dao/memory"
+	"GoGin/api/dao/mysql"
+	"GoGin/internal/config"
+	"log"
+)
+
+// repositories 持久化数据的仓库，由 STORAGE_DRIVER 决定使用 MySQL 还是内存实现
+type repositories struct {
+	user       dao.UserRepository
+	course     dao.CourseRepository
+	todo       dao.TodoRepository
+	session    dao.SessionRepository
+	token      dao.TokenRepository
+	twoFactor  dao.TwoFactorRepository
+	role       dao.RoleRepository
+	invitation dao.InvitationRepository
+	grant      dao.RoleGrantRepository
+	apiKey     dao.APIKeyRepository
+	identity   dao.IdentityRepository
+	audit      dao.AuditRepository
+}
+
+func newRepositories(cfg *config.Config, cacheClient cache.Cache) *repositories {
+	switch cfg.StorageDriver {
+	case "memory":
+		log.Println("使用内存存储，进程重启后数据丢失")
+		return newMemoryRepositories()
+	case "mysql", "":
+		return newMysqlRepositories(cfg, cacheClient)
+	default:
+		log.Fatalf("unknown STORAGE_DRIVER %q", cfg.StorageDriver)
+		return nil
+	}
+}
+
+func newMemoryRepositories() *repositories {
+	return &repositories{
+		user:       memory.NewMemoryUserRepo(),
+		course:     memory.NewMemoryCourseRepo(),
+		todo:       memory.NewMemoryTodoRepo(),
+		session:    memory.NewMemorySessionRepo(),
+		token:      memory.NewMemoryTokenRepo(),
+		twoFactor:  memory.NewMemoryTwoFactorRepo(),
+		role:       memory.NewMemoryRoleRepo(),
+		invitation: memory.NewMemoryInvitationRepo(),
+		grant:      memory.NewMemoryRoleGrantRepo(),
+		apiKey:     memory.NewMemoryAPIKeyRepo(),
+		identity:   memory.NewMemoryIdentityRepo(),
+		audit:      memory.NewMemoryAuditRepo(),
+	}
+}
+
+func newMysqlRepositories(cfg *config.Config, cacheClient cache.Cache) *repositories {
+	db, err := mysql.InitMysql(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &repositories{
+		user:       mysql.NewMysqlUserRepo(db, cacheClient),
+		course:     mysql.NewMysqlCourseRepo(db, cacheClient),
+		todo:       mysql.NewMysqlTodoRepo(db, cacheClient),
+		session:    mysql.NewMysqlSessionRepo(db, cacheClient),
+		token:      mysql.NewMysqlTokenRepo(db),
+		twoFactor:  mysql.NewMysqlTwoFactorRepo(db),
+		role:       mysql.NewMysqlRoleRepo(db, cacheClient),
+		invitation: mysql.NewMysqlInvitationRepo(db),
+		grant:      mysql.NewMysqlRoleGrantRepo(db),
+		apiKey:     mysql.NewMysqlAPIKeyRepo(db, cacheClient),
+		identity:   mysql.NewMysqlIdentityRepo(db),
+		audit:      mysql.NewMysqlAuditRepo(db),
+	}
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"GoGin/api/dao"
+	"GoGin/internal/model"
+	"errors"
+	"time"
+)
+
+// AuditLogger 追加一条审计日志
+type AuditLogger interface {
+	Log(entry *model.AuditEntry) error
+}
+
+type storeLogger struct {
+	repo dao.AuditRepository
+}
+
+// NewStoreLogger 写入数据库，供管理员查询
+func NewStoreLogger(repo dao.AuditRepository) AuditLogger {
+	return &storeLogger{repo: repo}
+}
+
+func (l *storeLogger) Log(entry *model.AuditEntry) error {
+	return l.repo.AppendAudit(entry)
+}
+
+type multiLogger struct {
+	loggers []AuditLogger
+}
+
+// NewMultiLogger 依次写入所有 logger，某一个失败不影响其余
+func NewMultiLogger(loggers ...AuditLogger) AuditLogger {
+	return &multiLogger{loggers: loggers}
+}
+
+func (l *multiLogger) Log(entry *model.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	var errs []error
+	for _, logger := range l.loggers {
+		if err := logger.Log(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,17 +15,145 @@ type RedisConfig struct {
 	DB       int
 }
 
+// CacheConfig Driver 为 redis / layered / memory / none，memory 只在本进程内有效，layered 为进程内 L1 + Redis L2
+type CacheConfig struct {
+	Driver     string
+	MaxEntries int // memory 最多保存的键数，超出后淘汰最久未使用的键
+	// layered 的 L1 过期时间和容量
+	L1TTLSeconds int
+	L1MaxEntries int
+}
+
+type MailConfig struct {
+	Driver   string // smtp / file / stdout
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	File     string
+}
+
+type VerificationConfig struct {
+	// 未验证邮箱的用户能否登录
+	RequiredForLogin bool
+	// 未验证邮箱的用户可以访问的路由组，"*" 表示全部
+	AllowedGroups []string
+	TTLHours      int
+}
+
+// LoginThrottleConfig 登录失败限流：达到 DelayAfter 次后按指数退避，达到 LockoutThreshold 次后锁定
+type LoginThrottleConfig struct {
+	AccountDelayAfter       int
+	AccountLockoutThreshold int
+	IPDelayAfter            int
+	IPLockoutThreshold      int
+	BaseDelaySeconds        int
+	MaxDelaySeconds         int
+	LockoutMinutes          int
+	// 失败计数的统计窗口
+	WindowMinutes int
+	// 统计窗口内同一邮箱、同一 IP 最多请求几次重置密码邮件，0 表示不限制
+	ResetPerEmail int
+	ResetPerIP    int
+}
+
+// PasswordPolicyConfig 密码策略，MaxLength 按字节计算
+type PasswordPolicyConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	AllowSymbols  bool
+	RejectCommon  bool
+}
+
+// PasswordHashConfig 新密码使用的哈希算法及参数，旧算法的哈希在登录时自动升级
+type PasswordHashConfig struct {
+	Algorithm         string // argon2id / bcrypt
+	BcryptCost        int
+	Argon2MemoryKiB   int
+	Argon2Iterations  int
+	Argon2Parallelism int
+}
+
+// OIDCProviderConfig 外部身份提供方，环境变量 OIDC_<NAME>_*
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
 type Config struct {
 	// jwt
 	JWTSecret      string
 	JWTIssuer      string
 	JWTExpireHours int
 
+	// jwt signing keys: HS256 使用 JWTSecret，RS256/EdDSA 使用 PEM 私钥
+	JWTAlgorithm      string
+	JWTKeyID          string
+	JWTPrivateKeyFile string
+	JWTVerifyKeys     string // "kid=path,kid=path" 轮换期间保留的旧公钥
+
+	// refresh session
+	RefreshExpireHours int
+
+	// 吊销存储不可用时是否放行
+	RevocationFailOpen bool
+
+	// storage: mysql / memory，memory 不连接 MySQL，数据只保存在进程内
+	StorageDriver string
+
 	// mysql
 	DSN string
 
 	//redis
 	Redis RedisConfig
+
+	// cache
+	Cache CacheConfig
+
+	// mail
+	Mail MailConfig
+	// 邮件中链接的前缀
+	AppBaseURL string
+
+	// password reset
+	PasswordResetTTLMinutes int
+
+	// email verification
+	Verification VerificationConfig
+
+	// two-factor
+	TOTPIssuer          string
+	StepUpMaxAgeMinutes int
+
+	// login brute-force protection
+	LoginThrottle LoginThrottleConfig
+
+	// account deletion: 注销后可恢复的天数，以及清理任务的执行间隔
+	AccountDeletionGraceDays    int
+	AccountPurgeIntervalMinutes int
+
+	// password policy & hashing
+	PasswordPolicy PasswordPolicyConfig
+	PasswordHash   PasswordHashConfig
+
+	// impersonation: 管理员模拟登录令牌的有效期
+	ImpersonationTTLMinutes int
+
+	// audit: 审计日志除写入数据库外，同时以 JSON Lines 追加到该文件，为空时不写文件
+	AuditLogFile string
+
+	// oidc: OIDC_PROVIDERS 列出提供方名称；已验证邮箱找不到用户时是否自动注册
+	OIDCProviders    []OIDCProviderConfig
+	OIDCAutoRegister bool
 }
 
 func LoadConfig() *Config {
@@ -32,19 +161,107 @@ func LoadConfig() *Config {
 	if err != nil {
 		log.Fatal("error loading .env file")
 	}
+	appBaseURL := getEnv("APP_BASE_URL", "http://localhost:8080")
 	return &Config{
-		JWTSecret:      getEnv("JWT_SECRET", ""),
-		JWTIssuer:      getEnv("JWT_ISSUER", ""),
-		JWTExpireHours: getEnvInt("JWT_EXPIRATION_HOURS", 24),
-		DSN:            getEnv("DB_DSN", ""),
+		JWTSecret:          getEnv("JWT_SECRET", ""),
+		JWTIssuer:          getEnv("JWT_ISSUER", ""),
+		JWTExpireHours:     getEnvInt("JWT_EXPIRATION_HOURS", 24),
+		JWTAlgorithm:       getEnv("JWT_ALGORITHM", "HS256"),
+		JWTKeyID:           getEnv("JWT_KEY_ID", "default"),
+		JWTPrivateKeyFile:  getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTVerifyKeys:      getEnv("JWT_VERIFY_KEYS", ""),
+		RefreshExpireHours: getEnvInt("REFRESH_EXPIRATION_HOURS", 24*7),
+		RevocationFailOpen: getEnvBool("REVOCATION_FAIL_OPEN", false),
+		StorageDriver:      getEnv("STORAGE_DRIVER", "mysql"),
+		DSN:                getEnv("DB_DSN", ""),
 		Redis: RedisConfig{
 			Addr:     getEnv("REDIS_ADDR", "127.0.0.1:6379"),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvInt("REDIS_DB", 0),
 		},
+		Cache: CacheConfig{
+			Driver:       getEnv("CACHE_DRIVER", "redis"),
+			MaxEntries:   getEnvInt("CACHE_MEMORY_MAX_ENTRIES", 10000),
+			L1TTLSeconds: getEnvInt("CACHE_L1_TTL_SECONDS", 30),
+			L1MaxEntries: getEnvInt("CACHE_L1_MAX_ENTRIES", 1000),
+		},
+		Mail: MailConfig{
+			Driver:   getEnv("MAIL_DRIVER", "stdout"),
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", ""),
+			File:     getEnv("MAIL_FILE", ""),
+		},
+		AppBaseURL:              appBaseURL,
+		PasswordResetTTLMinutes: getEnvInt("PASSWORD_RESET_TTL_MINUTES", 30),
+		TOTPIssuer:              getEnv("TOTP_ISSUER", "ClaranDemo"),
+		StepUpMaxAgeMinutes:     getEnvInt("STEP_UP_MAX_AGE_MINUTES", 0),
+		Verification: VerificationConfig{
+			RequiredForLogin: getEnvBool("EMAIL_VERIFICATION_REQUIRED_FOR_LOGIN", false),
+			AllowedGroups:    getEnvList("UNVERIFIED_ALLOWED_GROUPS", []string{"*"}),
+			TTLHours:         getEnvInt("EMAIL_VERIFICATION_TTL_HOURS", 48),
+		},
+		AuditLogFile:                getEnv("AUDIT_LOG_FILE", ""),
+		ImpersonationTTLMinutes:     getEnvInt("IMPERSONATION_TTL_MINUTES", 15),
+		OIDCProviders:               loadOIDCProviders(appBaseURL),
+		OIDCAutoRegister:            getEnvBool("OIDC_AUTO_REGISTER", true),
+		AccountDeletionGraceDays:    getEnvInt("ACCOUNT_DELETION_GRACE_DAYS", 30),
+		AccountPurgeIntervalMinutes: getEnvInt("ACCOUNT_PURGE_INTERVAL_MINUTES", 60),
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:     getEnvInt("PASSWORD_MAX_LENGTH", 0),
+			RequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+			RequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+			RequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			AllowSymbols:  getEnvBool("PASSWORD_ALLOW_SYMBOLS", true),
+			RejectCommon:  getEnvBool("PASSWORD_REJECT_COMMON", true),
+		},
+		PasswordHash: PasswordHashConfig{
+			Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:        getEnvInt("PASSWORD_BCRYPT_COST", 10),
+			Argon2MemoryKiB:   getEnvInt("PASSWORD_ARGON2_MEMORY_KIB", 64*1024),
+			Argon2Iterations:  getEnvInt("PASSWORD_ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvInt("PASSWORD_ARGON2_PARALLELISM", 2),
+		},
+		LoginThrottle: LoginThrottleConfig{
+			AccountDelayAfter:       getEnvInt("LOGIN_ACCOUNT_DELAY_AFTER", 3),
+			AccountLockoutThreshold: getEnvInt("LOGIN_ACCOUNT_LOCKOUT_THRESHOLD", 10),
+			IPDelayAfter:            getEnvInt("LOGIN_IP_DELAY_AFTER", 20),
+			IPLockoutThreshold:      getEnvInt("LOGIN_IP_LOCKOUT_THRESHOLD", 100),
+			BaseDelaySeconds:        getEnvInt("LOGIN_BASE_DELAY_SECONDS", 1),
+			MaxDelaySeconds:         getEnvInt("LOGIN_MAX_DELAY_SECONDS", 60),
+			LockoutMinutes:          getEnvInt("LOGIN_LOCKOUT_MINUTES", 15),
+			WindowMinutes:           getEnvInt("LOGIN_FAILURE_WINDOW_MINUTES", 15),
+			ResetPerEmail:           getEnvInt("PASSWORD_RESET_PER_EMAIL", 3),
+			ResetPerIP:              getEnvInt("PASSWORD_RESET_PER_IP", 20),
+		},
 	}
 }
 
+// loadOIDCProviders 读取 OIDC_PROVIDERS=school,github 及对应的 OIDC_SCHOOL_ISSUER 等配置
+func loadOIDCProviders(appBaseURL string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range getEnvList("OIDC_PROVIDERS", nil) {
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", strings.TrimSuffix(appBaseURL, "/")+"/user/oidc/"+name+"/callback"),
+			Scopes:       getEnvList(prefix+"SCOPES", nil),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			log.Fatalf("OIDC provider %s requires %sISSUER and %sCLIENT_ID", name, prefix, prefix)
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -59,3 +276,26 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvBool(key string, fallback bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getEnvList 逗号分隔的列表
+func getEnvList(key string, fallback []string) []string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return fallback
+	}
+	var values []string
+	for _, item := range strings.Split(valueStr, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileMailer 开发和测试用：把邮件追加写入文件，路径为空时输出到标准输出
+type fileMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path string) Mailer {
+	return &fileMailer{
+		path: path,
+	}
+}
+
+func (m *fileMailer) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var w io.Writer = os.Stdout
+	if m.path != "" {
+		f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	_, err := fmt.Fprintf(w, "==== %s ====\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), to, subject, body)
+	return err
+}
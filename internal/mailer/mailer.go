@@ -0,0 +1,26 @@
+package mailer
+
+import (
+	"GoGin/internal/config"
+	"log"
+)
+
+// Mailer 邮件发送
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer 根据 MAIL_DRIVER 选择实现：smtp / file / stdout
+func NewMailer(cfg *config.Config) Mailer {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From)
+	case "file":
+		return NewFileMailer(cfg.Mail.File)
+	case "", "stdout":
+		return NewFileMailer("")
+	default:
+		log.Fatal("unsupported mail driver:", cfg.Mail.Driver)
+		return nil
+	}
+}
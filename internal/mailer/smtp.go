@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"strings"
+)
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) Mailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &smtpMailer{
+		addr: fmt.Sprintf("%s:%d", host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (m *smtpMailer) Send(to, subject, body string) error {
+	msg, from, rcpt, err := buildMessage(m.from, to, subject, body)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, from, []string{rcpt}, msg)
+}
+
+// buildMessage 校验地址并拼接邮件，防止通过地址或主题中的换行注入额外的邮件头；返回邮件内容和信封地址
+func buildMessage(from, to, subject, body string) ([]byte, string, string, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, "", "", errors.New("invalid sender address")
+	}
+	toAddr, err := mail.ParseAddress(to)
+	if err != nil {
+		return nil, "", "", errors.New("invalid recipient address")
+	}
+	if strings.ContainsAny(subject, "\r\n") {
+		return nil, "", "", errors.New("invalid mail subject")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + fromAddr.String() + "\r\n")
+	msg.WriteString("To: " + toAddr.String() + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+	return []byte(msg.String()), fromAddr.Address, toAddr.Address, nil
+}
@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildMessageRejectsHeaderInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		to      string
+		subject string
+	}{
+		{"recipient with newline", "alice@school.edu\r\nBcc: eve@evil.com", "hi"},
+		{"recipient list", "alice@school.edu, eve@evil.com", "hi"},
+		{"subject with newline", "alice@school.edu", "hi\r\nBcc: eve@evil.com"},
+		{"subject with bare lf", "alice@school.edu", "hi\nBcc: eve@evil.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := buildMessage("noreply@school.edu", tt.to, tt.subject, "body"); err == nil {
+				t.Fatal("buildMessage accepted an injected header")
+			}
+		})
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	msg, from, rcpt, err := buildMessage("Demo <noreply@school.edu>", "alice@school.edu", "验证邮箱", "body")
+	if err != nil {
+		t.Fatalf("buildMessage: %v", err)
+	}
+	if from != "noreply@school.edu" || rcpt != "alice@school.edu" {
+		t.Fatalf("envelope = %q -> %q", from, rcpt)
+	}
+	header, body, _ := strings.Cut(string(msg), "\r\n\r\n")
+	if body != "body" || !strings.Contains(header, "Subject: =?utf-8?q?") || !strings.Contains(header, "To: <alice@school.edu>") {
+		t.Fatalf("message = %q", msg)
+	}
+}
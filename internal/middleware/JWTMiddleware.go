@@ -1,79 +1,331 @@
 package middleware
 
 import (
+	"GoGin/api/dao"
+	"GoGin/internal/config"
+	"GoGin/internal/model"
 	"GoGin/internal/util"
 	"GoGin/internal/util/jwt_util"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// PermissionChecker 查询角色是否拥有某项权限
+type PermissionChecker interface {
+	HasPermission(role, permission string) (bool, error)
+}
+
+// APIKeyAuthenticator 校验 API Key 并返回认证主体
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(rawKey string) (*model.Principal, error)
+}
+
+// SessionTracker 记录登录会话最近一次使用的时间和设备
+type SessionTracker interface {
+	TouchSession(sessionID string, client model.ClientInfo)
+}
+
 type JWTMiddleware struct {
-	jwtUtil jwt_util.Util
+	jwtUtil      jwt_util.Util
+	revocation   dao.RevocationRepository
+	permissions  PermissionChecker
+	apiKeys      APIKeyAuthenticator
+	sessions     SessionTracker
+	verification config.VerificationConfig
 }
 
-func NewJWTMiddleware(jwtUtil jwt_util.Util) *JWTMiddleware {
+func NewJWTMiddleware(jwtUtil jwt_util.Util, revocation dao.RevocationRepository, permissions PermissionChecker, apiKeys APIKeyAuthenticator, sessions SessionTracker, cfg *config.Config) *JWTMiddleware {
 	return &JWTMiddleware{
-		jwtUtil: jwtUtil,
+		jwtUtil:      jwtUtil,
+		revocation:   revocation,
+		permissions:  permissions,
+		apiKeys:      apiKeys,
+		sessions:     sessions,
+		verification: cfg.Verification,
 	}
 }
 
-// JWTAuthentication 进行jwt认证
+// JWTAuthentication 进行jwt认证，只接受登录签发的访问令牌
+// 修改密码、管理 API Key 等账号操作使用该中间件
 func (m *JWTMiddleware) JWTAuthentication() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authorizationHeader := c.GetHeader("Authorization")
-		if authorizationHeader == "" {
+		tokenString, ok := bearerToken(c)
+		if !ok {
 			util.Error(c, 401, "未登录！") // 未登录
 			c.Abort()
 			return
 		}
+		m.authenticateJWT(c, tokenString)
+	}
+}
+
+// Authenticate 同时接受访问令牌和 API Key
+// API Key 可放在 "X-API-Key" 头，或以 "Bearer cdk_..." 形式放在 Authorization 头
+func (m *JWTMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rawKey := c.GetHeader("X-API-Key"); rawKey != "" {
+			m.authenticateAPIKey(c, rawKey)
+			return
+		}
 
-		parts := strings.SplitN(authorizationHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		tokenString, ok := bearerToken(c)
+		if !ok {
 			util.Error(c, 401, "未登录！")
 			c.Abort()
 			return
 		}
+		if strings.HasPrefix(tokenString, model.APIKeyPrefix) {
+			m.authenticateAPIKey(c, tokenString)
+			return
+		}
+		m.authenticateJWT(c, tokenString)
+	}
+}
 
-		tokenString := parts[1]
+// RequireScope API Key 需要包含指定范围，登录令牌不受限制
+func (m *JWTMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			util.Error(c, 401, "未登录！")
+			c.Abort()
+			return
+		}
+		if !principal.HasScope(scope) {
+			util.Error(c, 403, "API Key 无权访问！")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
 
-		token, err := m.jwtUtil.ValidateToken(tokenString)
-		if err != nil {
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				util.Error(c, 401, "Token is expired")
+func (m *JWTMiddleware) authenticateAPIKey(c *gin.Context, rawKey string) {
+	if m.apiKeys == nil {
+		util.Error(c, 401, "API Key is invalid")
+		c.Abort()
+		return
+	}
+	principal, err := m.apiKeys.AuthenticateAPIKey(rawKey)
+	if err != nil {
+		util.Error(c, 401, "API Key is invalid")
+		c.Abort()
+		return
+	}
+
+	SetPrincipal(c, principal)
+	c.Next()
+}
+
+func (m *JWTMiddleware) authenticateJWT(c *gin.Context, tokenString string) {
+	token, err := m.jwtUtil.ValidateToken(tokenString)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			util.Error(c, 401, "Token is expired")
+			c.Abort()
+			return
+		}
+		util.Error(c, 401, "Token is invalid")
+		c.Abort()
+		return
+	}
+
+	claims, err := m.jwtUtil.ExtractClaims(token)
+	if err != nil {
+		util.Error(c, 500, "Failed to extract claims")
+		c.Abort()
+		return
+	}
+	// 登录挑战等其他用途的令牌不能当作访问令牌
+	if claims.TokenType != jwt_util.TokenTypeAccess {
+		util.Error(c, 401, "Token is invalid")
+		c.Abort()
+		return
+	}
+
+	// 吊销检查
+	if revoked, err := m.isRevoked(claims); err != nil {
+		util.Error(c, 503, "Token revocation check unavailable")
+		c.Abort()
+		return
+	} else if revoked {
+		util.Error(c, 401, "Token is revoked")
+		c.Abort()
+		return
+	}
+
+	if m.sessions != nil {
+		m.sessions.TouchSession(claims.SessionID, ClientInfo(c))
+	}
+
+	SetPrincipal(c, newPrincipal(claims))
+	c.Next()
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	authorizationHeader := c.GetHeader("Authorization")
+	if authorizationHeader == "" {
+		return "", false
+	}
+	parts := strings.SplitN(authorizationHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// JWTAuthorization 鉴权：进入管理接口
+func (m *JWTMiddleware) JWTAuthorization() gin.HandlerFunc {
+	return m.Require(model.PermAdminAccess)
+}
+
+// Require 要求当前角色拥有全部指定权限
+func (m *JWTMiddleware) Require(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			util.Error(c, 401, "未登录！")
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			allowed, err := m.permissions.HasPermission(principal.Role, permission)
+			if err != nil || !allowed || !principal.HasScope(permission) {
+				util.Error(c, 403, "无权限！")
 				c.Abort()
 				return
 			}
-			util.Error(c, 401, "Token is invalid")
+		}
+		c.Next()
+	}
+}
+
+// BlockImpersonation 模拟登录的令牌不能执行修改密码等敏感操作
+func (m *JWTMiddleware) BlockImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			util.Error(c, 401, "未登录！")
+			c.Abort()
+			return
+		}
+		if principal.IsImpersonation() {
+			util.Error(c, 403, "模拟登录不能执行该操作！")
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
+
+// RequireRecentOTP 要求令牌在 maxAge 内通过 TOTP 认证（step-up），maxAge<=0 时不做要求；
+// API Key 主体永远不带 amr=otp，开启后直接拒绝
+func (m *JWTMiddleware) RequireRecentOTP(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxAge <= 0 {
+			c.Next()
+			return
+		}
 
-		claims, err := m.jwtUtil.ExtractClaims(token)
-		if err != nil {
-			util.Error(c, 500, "Failed to extract claims")
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			util.Error(c, 401, "未登录！")
+			c.Abort()
+			return
+		}
+
+		//API Key 没有交互式认证，无法完成 step-up
+		if principal.APIKeyID != 0 {
+			util.Error(c, 403, "该操作需要双因素认证，不能使用 API Key！")
 			c.Abort()
 			return
 		}
 
-		c.Set("username", claims["username"])
-		c.Set("user_id", claims["user_id"])
-		c.Set("role", claims["role"])
+		hasOTP := false
+		for _, method := range principal.AMR {
+			if method == jwt_util.AMROTP {
+				hasOTP = true
+			}
+		}
+		if !hasOTP || time.Since(principal.AuthTime) > maxAge {
+			util.Error(c, 403, "请先完成双因素认证！")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
 
-// JWTAuthorization 鉴权
-func (m *JWTMiddleware) JWTAuthorization() gin.HandlerFunc {
+// RequireVerifiedEmail 未验证邮箱的用户只能访问配置允许的路由组
+func (m *JWTMiddleware) RequireVerifiedEmail(group string) gin.HandlerFunc {
+	allowed := false
+	for _, g := range m.verification.AllowedGroups {
+		if g == "*" || g == group {
+			allowed = true
+		}
+	}
+
 	return func(c *gin.Context) {
-		role, _ := c.Get("role")
-		if role != "admin" {
-			util.Error(c, 403, "无权限！")
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			util.Error(c, 401, "未登录！")
+			c.Abort()
+			return
+		}
+		if !principal.EmailVerified && !allowed {
+			util.Error(c, 403, "请先验证邮箱！")
 			c.Abort()
 			return
 		}
 		c.Next()
 	}
 }
+
+// isRevoked 检查 jti 黑名单、会话吊销和用户级水位线
+func (m *JWTMiddleware) isRevoked(claims *jwt_util.Claims) (bool, error) {
+	if m.revocation == nil {
+		return false, nil
+	}
+
+	if revoked, err := m.revocation.IsTokenRevoked(claims.ID); err != nil || revoked {
+		return revoked, err
+	}
+	if revoked, err := m.revocation.IsSessionRevoked(claims.SessionID); err != nil || revoked {
+		return revoked, err
+	}
+
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	return m.revocation.IsUserTokenRevoked(claims.UserID, claims.IssuedAt.Time)
+}
+
+func newPrincipal(claims *jwt_util.Claims) *model.Principal {
+	principal := &model.Principal{
+		UserID:    claims.UserID,
+		Username:  claims.Username,
+		Role:      claims.Role,
+		TokenID:   claims.ID,
+		SessionID: claims.SessionID,
+		Scopes:    claims.Scopes,
+
+		EmailVerified: claims.EmailVerified,
+		AMR:           claims.AMR,
+	}
+	if claims.AuthTime != nil {
+		principal.AuthTime = claims.AuthTime.Time
+	}
+	if claims.ExpiresAt != nil {
+		principal.ExpiresAt = claims.ExpiresAt.Time
+	}
+	if claims.Actor != nil {
+		principal.ImpersonatorID = claims.Actor.UserID
+		principal.ImpersonatorName = claims.Actor.Username
+	}
+	return principal
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"GoGin/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+// SetPrincipal 由认证中间件调用，每个请求只写入一次
+func SetPrincipal(c *gin.Context, principal *model.Principal) {
+	c.Set(principalKey, principal)
+}
+
+// GetPrincipal 读取当前认证主体，未认证时返回 false
+func GetPrincipal(c *gin.Context) (*model.Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*model.Principal)
+	if !ok || principal == nil {
+		return nil, false
+	}
+	return principal, true
+}
+
+// CurrentUserID 读取当前用户ID，未认证时返回 false
+func CurrentUserID(c *gin.Context) (int, bool) {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return 0, false
+	}
+	return principal.UserID, true
+}
+
+// ClientInfo 请求来源的 IP 和 User-Agent
+func ClientInfo(c *gin.Context) model.ClientInfo {
+	return model.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"GoGin/internal/util"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+)
+
+// validRequestID 只信任格式安全的上游请求ID，避免日志注入
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID 沿用上游传入的 X-Request-ID，没有时生成一个，并写回响应头
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID, _ = util.RandomID(8)
+		}
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// GetRequestID 当前请求的ID，未经过 RequestID 中间件时为空
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// APIKeyPrefix API Key 明文前缀，用于和 JWT 区分
+const APIKeyPrefix = "cdk_"
+
+// API Key 可申请的路由组范围，另外还可以申请当前角色拥有的权限
+const (
+	ScopeCourse = "course"
+	ScopeTodo   = "to-do"
+)
+
+var APIKeyGroupScopes = []string{ScopeCourse, ScopeTodo}
+
+// APIKey 个人访问令牌，只保存哈希
+type APIKey struct {
+	ID         int        `json:"api_key_id" gorm:"primary_key;auto_increment;column:api_key_id"`
+	UserID     int        `json:"user_id" gorm:"column:user_id;index"`
+	Name       string     `json:"name" gorm:"column:name;type:varchar(100)"`
+	Hint       string     `json:"hint" gorm:"column:hint;type:varchar(16)"` // 明文前几位，便于辨认
+	KeyHash    string     `json:"-" gorm:"column:key_hash;uniqueIndex;type:varchar(64)"`
+	Scopes     []string   `json:"scopes" gorm:"column:scopes;serializer:json;type:varchar(512)"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at"`
+}
@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// 审计动作
+const (
+	AuditActionRegister       = "user.register"
+	AuditActionLogin          = "user.login"
+	AuditActionLoginOTP       = "user.login_2fa"
+	AuditActionLoginOIDC      = "user.login_oidc"
+	AuditActionRefresh        = "user.refresh"
+	AuditActionLogout         = "user.logout"
+	AuditActionPasswordReset  = "user.password_reset"
+	AuditActionPasswordChange = "user.password_change"
+	AuditActionEmailChange    = "user.email_change"
+	AuditActionAccountDelete  = "user.account_delete"
+	AuditActionSessionRevoke  = "user.session_revoke"
+	AuditActionAPIKeyCreate   = "user.api_key_create"
+	AuditActionAPIKeyRevoke   = "user.api_key_revoke"
+	AuditActionTwoFactor      = "user.2fa"
+	AuditActionCourseAdd      = "course.add"
+	AuditActionRoleCreate     = "admin.role_create"
+	AuditActionRolePermission = "admin.role_permissions"
+	AuditActionRoleAssign     = "admin.role_assign"
+	AuditActionInviteCreate   = "admin.invitation_create"
+	AuditActionInviteRevoke   = "admin.invitation_revoke"
+	AuditActionRoleApprove    = "admin.role_request_approve"
+	AuditActionRoleReject     = "admin.role_request_reject"
+	AuditActionUserLogout     = "admin.user_logout"
+	AuditActionUserUnlock     = "admin.user_unlock"
+	AuditActionUserDisable    = "admin.user_disable"
+	AuditActionUserEnable     = "admin.user_enable"
+	AuditActionImpersonate    = "admin.impersonate"
+	// 模拟登录期间的每个请求
+	AuditActionImpersonatedRequest = "impersonation.request"
+)
+
+// 审计结果
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEntry 审计日志，只追加不修改
+type AuditEntry struct {
+	ID        int       `json:"id" gorm:"primary_key;auto_increment;column:audit_id"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+	// 操作者，未登录的操作（如登录失败）为 0
+	ActorID   int    `json:"actor_id" gorm:"column:actor_id;index"`
+	ActorName string `json:"actor_name" gorm:"column:actor_name;type:varchar(100)"`
+	// 模拟登录时为实际操作的管理员，ActorID 为被模拟的用户
+	ImpersonatorID int    `json:"impersonator_id,omitempty" gorm:"column:impersonator_id;index"`
+	Action         string `json:"action" gorm:"column:action;type:varchar(50);index"`
+	Target         string `json:"target" gorm:"column:target;type:varchar(255)"`
+	IP             string `json:"ip" gorm:"column:ip;type:varchar(64)"`
+	UserAgent      string `json:"user_agent" gorm:"column:user_agent;type:varchar(255)"`
+	RequestID      string `json:"request_id" gorm:"column:request_id;type:varchar(64)"`
+	Outcome        string `json:"outcome" gorm:"column:outcome;type:varchar(20)"`
+	Status         int    `json:"status" gorm:"column:status"`
+	Detail         string `json:"detail,omitempty" gorm:"column:detail;type:varchar(255)"`
+}
+
+// AuditFilter 审计查询条件，零值表示不过滤；按 id 倒序游标分页
+type AuditFilter struct {
+	ActorID int
+	Action  string
+	From    time.Time
+	To      time.Time
+	// 上一页最后一条的 id
+	Cursor int
+	Limit  int
+}
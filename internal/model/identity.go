@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// UserIdentity 绑定到本地用户的外部身份，同一提供方的 sub 唯一
+type UserIdentity struct {
+	ID        int       `json:"identity_id" gorm:"primary_key;auto_increment;column:identity_id"`
+	UserID    int       `json:"user_id" gorm:"column:user_id;index"`
+	Provider  string    `json:"provider" gorm:"column:provider;type:varchar(50);uniqueIndex:idx_provider_subject"`
+	Subject   string    `json:"subject" gorm:"column:subject;type:varchar(255);uniqueIndex:idx_provider_subject"`
+	Email     string    `json:"email" gorm:"column:email;type:varchar(100)"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// OIDCState 授权请求发起时保存，回调时按 state 取出
+type OIDCState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	// 非 0 表示已登录用户发起的绑定请求
+	LinkUserID int `json:"link_user_id"`
+}
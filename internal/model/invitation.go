@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// 角色申请状态
+const (
+	RoleRequestPending  = "pending"
+	RoleRequestApproved = "approved"
+	RoleRequestRejected = "rejected"
+)
+
+// 角色授予来源
+const (
+	GrantSourceAdmin      = "admin"
+	GrantSourceInvitation = "invitation"
+	GrantSourceApproval   = "approval"
+)
+
+// Invitation 管理员签发的邀请码，注册时获得指定角色
+type Invitation struct {
+	ID        int        `json:"invitation_id" gorm:"primary_key;auto_increment;column:invitation_id"`
+	CodeHash  string     `json:"-" gorm:"column:code_hash;uniqueIndex;type:varchar(64)"`
+	Role      string     `json:"role" gorm:"column:role;type:varchar(50)"`
+	MaxUses   int        `json:"max_uses" gorm:"column:max_uses"`
+	Uses      int        `json:"uses" gorm:"column:uses"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	CreatedBy int        `json:"created_by" gorm:"column:created_by"`
+	RevokedAt *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+// RoleRequest 用户提交、管理员审批的角色申请
+type RoleRequest struct {
+	ID        int        `json:"request_id" gorm:"primary_key;auto_increment;column:request_id"`
+	UserID    int        `json:"user_id" gorm:"column:user_id;index"`
+	Role      string     `json:"role" gorm:"column:role;type:varchar(50)"`
+	Reason    string     `json:"reason" gorm:"column:reason;type:varchar(255)"`
+	Status    string     `json:"status" gorm:"column:status;type:varchar(20);index"`
+	DecidedBy *int       `json:"decided_by" gorm:"column:decided_by"`
+	Note      string     `json:"note" gorm:"column:note;type:varchar(255)"`
+	DecidedAt *time.Time `json:"decided_at" gorm:"column:decided_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+// RoleGrant 角色授予记录（只追加）
+type RoleGrant struct {
+	ID           int       `json:"grant_id" gorm:"primary_key;auto_increment;column:grant_id"`
+	UserID       int       `json:"user_id" gorm:"column:user_id;index"`
+	Role         string    `json:"role" gorm:"column:role;type:varchar(50)"`
+	PreviousRole string    `json:"previous_role" gorm:"column:previous_role;type:varchar(50)"`
+	Source       string    `json:"source" gorm:"column:source;type:varchar(20)"`
+	SourceID     int       `json:"source_id" gorm:"column:source_id"`
+	GrantedBy    int       `json:"granted_by" gorm:"column:granted_by"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at"`
+}
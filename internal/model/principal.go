@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Principal 当前请求的认证主体，由认证中间件写入 gin context
+type Principal struct {
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	TokenID   string    `json:"token_id"`
+	SessionID string    `json:"session_id,omitempty"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// 邮箱是否已验证
+	EmailVerified bool `json:"email_verified"`
+	// 认证方式及最近一次强认证时间
+	AMR      []string  `json:"amr,omitempty"`
+	AuthTime time.Time `json:"auth_time"`
+	// 通过 API Key 认证时为 Key 的 ID，此时只能访问 Scopes 覆盖的接口
+	APIKeyID int `json:"api_key_id,omitempty"`
+	// 模拟登录时为发起模拟的管理员
+	ImpersonatorID   int    `json:"impersonator_id,omitempty"`
+	ImpersonatorName string `json:"impersonator_name,omitempty"`
+}
+
+// IsImpersonation 当前令牌是否由管理员模拟登录签发
+func (p *Principal) IsImpersonation() bool {
+	return p.ImpersonatorID != 0
+}
+
+// HasScope JWT 登录不受范围限制；API Key 需要包含该范围
+func (p *Principal) HasScope(scope string) bool {
+	if p.APIKeyID == 0 {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
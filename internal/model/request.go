@@ -1,11 +1,14 @@
 package model
 
+import "time"
+
 // RegisterRequest "/register"
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 	Email    string `json:"email" binding:"required"`
-	Role     string `json:"role" binding:"required"`
+	// 邀请码，公开注册只能创建普通用户
+	InviteCode string `json:"invite_code"`
 }
 
 // LoginRequest "/login"
@@ -50,3 +53,126 @@ type FinishTodoRequest struct {
 type DeleteTodoRequest struct {
 	TodoID int `json:"todo_id" binding:"required"`
 }
+
+// LogoutRequest "/logout"
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ForgotPasswordRequest "/password/forgot"
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// ResetPasswordRequest "/password/reset"
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// VerifyEmailRequest "/verify"
+type VerifyEmailRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// LoginSecondFactorRequest "/login/2fa"
+type LoginSecondFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// TwoFactorCodeRequest "/2fa/confirm" "/2fa/disable" "/2fa/recovery-codes" "/2fa/step-up"
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// CreateRoleRequest "/admin/roles"
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// SetPermissionsRequest "/admin/roles/:name/permissions"
+type SetPermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// AssignRoleRequest "/admin/users/:id/role"
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// CreateInvitationRequest "/admin/invitations"
+type CreateInvitationRequest struct {
+	Role           string `json:"role" binding:"required"`
+	MaxUses        int    `json:"max_uses" binding:"required,min=1"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"required,min=1"`
+}
+
+// RoleRequestRequest "/user/role-requests"
+type RoleRequestRequest struct {
+	Role   string `json:"role" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// DecideRoleRequestRequest "/admin/role-requests/:id/approve" "/admin/role-requests/:id/reject"
+type DecideRoleRequestRequest struct {
+	Note string `json:"note"`
+}
+
+// ChangePasswordRequest "/user/password"
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ChangeUsernameRequest "/user/username"
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// ChangeEmailRequest "/user/email"
+type ChangeEmailRequest struct {
+	Password string `json:"password" binding:"required"`
+	NewEmail string `json:"new_email" binding:"required"`
+}
+
+// DeleteAccountRequest "/user/account"
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// ListUsersRequest "/admin/users"
+type ListUsersRequest struct {
+	Role          string    `form:"role"`
+	EmailDomain   string    `form:"email_domain"`
+	CreatedAfter  time.Time `form:"created_after" time_format:"2006-01-02"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02"`
+	Cursor        int       `form:"cursor" binding:"min=0"`
+	Limit         int       `form:"limit" binding:"min=0,max=100"`
+}
+
+// ListAuditRequest "/admin/audit"，时间为 RFC 3339 格式
+type ListAuditRequest struct {
+	ActorID int       `form:"actor_id" binding:"min=0"`
+	Action  string    `form:"action"`
+	From    time.Time `form:"from"`
+	To      time.Time `form:"to"`
+	Cursor  int       `form:"cursor" binding:"min=0"`
+	Limit   int       `form:"limit" binding:"min=0,max=100"`
+}
+
+// CreateAPIKeyRequest "/user/api-keys"
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"required,min=1,max=365"`
+}
+
+// OIDCCallbackRequest "/user/oidc/:provider/callback"
+type OIDCCallbackRequest struct {
+	Code  string `form:"code"`
+	State string `form:"state"`
+	Error string `form:"error"`
+}
@@ -0,0 +1,50 @@
+package model
+
+// 内置角色
+const (
+	RoleAdmin             = "admin"
+	RoleInstructor        = "instructor"
+	RoleTeachingAssistant = "teaching_assistant"
+	RoleUser              = "user"
+)
+
+// 权限
+const (
+	PermAdminAccess  = "admin:access"  // 进入 /admin 管理接口
+	PermCourseCreate = "course:create" // 新增课程
+	PermUserManage   = "user:manage"   // 管理用户
+	PermRoleManage   = "role:manage"   // 管理角色与权限分配
+	PermAuditRead    = "audit:read"    // 查看审计日志
+)
+
+// Role 角色
+type Role struct {
+	ID          int          `json:"role_id" gorm:"primary_key;auto_increment;column:role_id"`
+	Name        string       `json:"name" gorm:"column:name;uniqueIndex;type:varchar(50)"`
+	Description string       `json:"description" gorm:"column:description;type:varchar(255)"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;joinForeignKey:RoleID;joinReferences:PermissionID"`
+}
+
+// Permission 权限
+type Permission struct {
+	ID          int    `json:"permission_id" gorm:"primary_key;auto_increment;column:permission_id"`
+	Name        string `json:"name" gorm:"column:name;uniqueIndex;type:varchar(50)"`
+	Description string `json:"description" gorm:"column:description;type:varchar(255)"`
+}
+
+// DefaultPermissions 启动时写入的权限
+var DefaultPermissions = []Permission{
+	{Name: PermAdminAccess, Description: "access admin APIs"},
+	{Name: PermCourseCreate, Description: "create courses"},
+	{Name: PermUserManage, Description: "manage users"},
+	{Name: PermRoleManage, Description: "manage roles and role assignments"},
+	{Name: PermAuditRead, Description: "read the security audit log"},
+}
+
+// DefaultRoles 启动时写入的角色及其权限；已存在的角色不会被覆盖，只补齐缺少的默认权限
+var DefaultRoles = map[string][]string{
+	RoleAdmin:             {PermAdminAccess, PermCourseCreate, PermUserManage, PermRoleManage, PermAuditRead},
+	RoleInstructor:        {PermCourseCreate},
+	RoleTeachingAssistant: {},
+	RoleUser:              {},
+}
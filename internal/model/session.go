@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Session 刷新令牌会话（同一登录产生的令牌族）
+type Session struct {
+	SessionID string    `json:"session_id" gorm:"primary_key;column:session_id;type:varchar(64)"`
+	UserID    int       `json:"user_id" gorm:"column:user_id;index"`
+	TokenHash string    `json:"-" gorm:"column:token_hash;type:varchar(64)"`
+	Revoked   bool      `json:"revoked" gorm:"column:revoked"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"column:expires_at"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+	// 登录设备信息，使用令牌时更新
+	UserAgent  string    `json:"user_agent" gorm:"column:user_agent;type:varchar(255)"`
+	IP         string    `json:"ip" gorm:"column:ip;type:varchar(64)"`
+	LastSeenAt time.Time `json:"last_seen_at" gorm:"column:last_seen_at"`
+	// 是否为发起请求的会话，只用于列表展示
+	Current bool `json:"current" gorm:"-"`
+}
+
+// ClientInfo 发起请求的客户端
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
@@ -9,4 +9,6 @@ type TodoTask struct {
 	Description string    `json:"description" gorm:"column:description"`
 	Completed   bool      `json:"completed" gorm:"column:completed"`
 	CreatedTime time.Time `json:"due_date" gorm:"column:due_date"`
+	// 账号注销时归档，恢复账号时取消归档
+	ArchivedAt *time.Time `json:"-" gorm:"column:archived_at;index"`
 }
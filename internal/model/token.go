@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// 一次性令牌用途
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeChangeEmail   = "change_email" // Payload 保存新邮箱
+	TokenPurposeRestore       = "restore_account"
+)
+
+// OneTimeToken 一次性令牌，只保存哈希
+type OneTimeToken struct {
+	ID        int        `json:"id" gorm:"primary_key;auto_increment;column:id"`
+	UserID    int        `json:"user_id" gorm:"column:user_id;index"`
+	Purpose   string     `json:"purpose" gorm:"column:purpose;type:varchar(32)"`
+	TokenHash string     `json:"-" gorm:"column:token_hash;uniqueIndex;type:varchar(64)"`
+	Payload   string     `json:"-" gorm:"column:payload;type:varchar(255)"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+}
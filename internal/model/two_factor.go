@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// TwoFactor TOTP 双因素认证配置
+type TwoFactor struct {
+	UserID       int        `json:"user_id" gorm:"primary_key;column:user_id"`
+	Secret       string     `json:"-" gorm:"column:secret;type:varchar(64)"`
+	Enabled      bool       `json:"enabled" gorm:"column:enabled"`
+	ConfirmedAt  *time.Time `json:"confirmed_at" gorm:"column:confirmed_at"`
+	LastUsedStep int64      `json:"-" gorm:"column:last_used_step"` // 防止同一验证码被重放
+	CreatedAt    time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// RecoveryCode 恢复码，只保存哈希
+type RecoveryCode struct {
+	ID       int        `json:"id" gorm:"primary_key;auto_increment;column:id"`
+	UserID   int        `json:"user_id" gorm:"column:user_id;index"`
+	CodeHash string     `json:"-" gorm:"column:code_hash;type:varchar(64)"`
+	UsedAt   *time.Time `json:"used_at" gorm:"column:used_at"`
+}
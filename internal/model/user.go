@@ -1,5 +1,11 @@
 package model
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 // memory used
 //
 //	type User struct {
@@ -16,4 +22,23 @@ type User struct {
 	Email    string `json:"email" gorm:"column:email;uniqueIndex;type:varchar(100)"`
 	Password string `json:"-" gorm:"column:password;type:varchar(255)"`
 	Role     string `json:"role" gorm:"column:role;type:varchar(50)"`
+	// 邮箱验证状态
+	EmailVerified   bool       `json:"email_verified" gorm:"column:email_verified;default:false"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at" gorm:"column:email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"column:created_at;index"`
+	// 管理员停用
+	DisabledAt *time.Time `json:"disabled_at" gorm:"column:disabled_at"`
+	// 用户注销，宽限期内可恢复，之后彻底删除
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;index"`
+}
+
+// UserFilter 管理员查询用户的条件，零值表示不过滤；按 user_id 升序游标分页
+type UserFilter struct {
+	Role          string
+	EmailDomain   string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// 上一页最后一个 user_id
+	Cursor int
+	Limit  int
 }
@@ -0,0 +1,80 @@
+package hasher
+
+import (
+	"GoGin/internal/config"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 算法名称，同时是编码后哈希的前缀标识
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Hasher 密码哈希算法，编码结果自带算法和参数，可以直接存库
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify 比对明文和编码后的哈希
+	Verify(encoded, password string) (bool, error)
+	// Matches 该编码是否由本算法生成
+	Matches(encoded string) bool
+	// NeedsRehash 参数比当前配置弱时需要重新哈希
+	NeedsRehash(encoded string) bool
+}
+
+// Manager 用首选算法生成新哈希，按前缀识别并校验所有支持的旧算法
+type Manager struct {
+	preferred Hasher
+	hashers   []Hasher
+}
+
+func NewManager(preferred Hasher, others ...Hasher) *Manager {
+	return &Manager{
+		preferred: preferred,
+		hashers:   append([]Hasher{preferred}, others...),
+	}
+}
+
+// NewFromConfig 根据配置选择首选算法，另一种算法仍可用于校验
+func NewFromConfig(cfg config.PasswordHashConfig) (*Manager, error) {
+	argon := NewArgon2id(Argon2Params{
+		Memory:      uint32(cfg.Argon2MemoryKiB),
+		Iterations:  uint32(cfg.Argon2Iterations),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+	bcrypt := NewBcrypt(cfg.BcryptCost)
+
+	switch strings.ToLower(cfg.Algorithm) {
+	case "", AlgorithmArgon2id:
+		return NewManager(argon, bcrypt), nil
+	case AlgorithmBcrypt:
+		return NewManager(bcrypt, argon), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.Algorithm)
+	}
+}
+
+func (m *Manager) Hash(password string) (string, error) {
+	return m.preferred.Hash(password)
+}
+
+// Verify 返回密码是否正确，以及正确时是否需要用首选算法重新哈希
+func (m *Manager) Verify(encoded, password string) (ok bool, rehash bool) {
+	for _, h := range m.hashers {
+		if !h.Matches(encoded) {
+			continue
+		}
+		ok, err := h.Verify(encoded, password)
+		if err != nil || !ok {
+			return false, false
+		}
+		return true, h != m.preferred || h.NeedsRehash(encoded)
+	}
+	return false, false
+}
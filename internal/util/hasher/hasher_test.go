@@ -0,0 +1,116 @@
+package hasher
+
+import (
+	"GoGin/internal/config"
+	"strings"
+	"testing"
+)
+
+// 测试使用最低参数，避免拖慢测试
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func TestManagerVerify(t *testing.T) {
+	argon := NewArgon2id(testArgon2Params)
+	bcrypt := NewBcrypt(4)
+	m := NewManager(argon, bcrypt)
+
+	encoded, err := m.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$") {
+		t.Fatalf("Hash = %q, want argon2id encoding", encoded)
+	}
+	if ok, rehash := m.Verify(encoded, "secret"); !ok || rehash {
+		t.Fatalf("Verify(preferred) = %v, %v, want true, false", ok, rehash)
+	}
+	if ok, _ := m.Verify(encoded, "wrong"); ok {
+		t.Fatal("Verify accepted a wrong password")
+	}
+
+	// 旧算法的哈希仍可登录，并提示用首选算法重新哈希
+	legacy, err := bcrypt.Hash("secret")
+	if err != nil {
+		t.Fatalf("bcrypt Hash: %v", err)
+	}
+	if ok, rehash := m.Verify(legacy, "secret"); !ok || !rehash {
+		t.Fatalf("Verify(legacy) = %v, %v, want true, true", ok, rehash)
+	}
+	if ok, rehash := m.Verify(legacy, "wrong"); ok || rehash {
+		t.Fatalf("Verify(legacy, wrong) = %v, %v, want false, false", ok, rehash)
+	}
+
+	for _, encoded := range []string{"", "plaintext", "$unknown$abc"} {
+		if ok, rehash := m.Verify(encoded, "secret"); ok || rehash {
+			t.Fatalf("Verify(%q) = %v, %v, want false, false", encoded, ok, rehash)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	weak, err := NewArgon2id(testArgon2Params).Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	stronger := NewArgon2id(Argon2Params{Memory: 64, Iterations: 2, Parallelism: 1})
+	if !stronger.NeedsRehash(weak) {
+		t.Fatal("argon2id hash with fewer iterations should need rehash")
+	}
+	if NewArgon2id(testArgon2Params).NeedsRehash(weak) {
+		t.Fatal("argon2id hash with current params should not need rehash")
+	}
+
+	cheap, err := NewBcrypt(4).Hash("secret")
+	if err != nil {
+		t.Fatalf("bcrypt Hash: %v", err)
+	}
+	if !NewBcrypt(5).NeedsRehash(cheap) {
+		t.Fatal("bcrypt hash with lower cost should need rehash")
+	}
+	if NewBcrypt(4).NeedsRehash(cheap) {
+		t.Fatal("bcrypt hash with current cost should not need rehash")
+	}
+
+	// 参数变强后，Manager 校验成功时要求重新哈希
+	m := NewManager(stronger)
+	if ok, rehash := m.Verify(weak, "secret"); !ok || !rehash {
+		t.Fatalf("Verify(weak) = %v, %v, want true, true", ok, rehash)
+	}
+}
+
+func TestNewFromConfigPreferredAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		prefix    string
+	}{
+		{"", "$argon2id$"},
+		{"argon2id", "$argon2id$"},
+		{"bcrypt", "$2"},
+	}
+	for _, tt := range tests {
+		m, err := NewFromConfig(configFor(tt.algorithm))
+		if err != nil {
+			t.Fatalf("NewFromConfig(%q): %v", tt.algorithm, err)
+		}
+		encoded, err := m.Hash("secret")
+		if err != nil {
+			t.Fatalf("Hash: %v", err)
+		}
+		if !strings.HasPrefix(encoded, tt.prefix) {
+			t.Fatalf("NewFromConfig(%q) hash = %q, want prefix %q", tt.algorithm, encoded, tt.prefix)
+		}
+	}
+	if _, err := NewFromConfig(configFor("md5")); err == nil {
+		t.Fatal("NewFromConfig accepted an unknown algorithm")
+	}
+}
+
+func configFor(algorithm string) config.PasswordHashConfig {
+	return config.PasswordHashConfig{
+		Algorithm:         algorithm,
+		BcryptCost:        4,
+		Argon2MemoryKiB:   64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	}
+}
@@ -0,0 +1,42 @@
+package jwt_util
+
+import "github.com/golang-jwt/jwt/v5"
+
+// 令牌类型，只有 access 可以通过认证中间件
+const (
+	TokenTypeAccess       = "access"
+	TokenTypeMFAChallenge = "mfa_challenge"
+)
+
+// RFC 8176 认证方式；oidc 表示由外部身份提供方完成认证
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+	AMROIDC     = "oidc"
+)
+
+// Actor RFC 8693 "act" 声明：代替令牌主体执行操作的管理员
+type Actor struct {
+	Subject  string `json:"sub"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// Claims 访问令牌声明
+type Claims struct {
+	TokenType string   `json:"typ"`
+	UserID    int      `json:"user_id"`
+	Username  string   `json:"username"`
+	Role      string   `json:"role"`
+	Scopes    []string `json:"scopes,omitempty"`
+	// 签发该令牌的登录会话
+	SessionID string `json:"sid,omitempty"`
+	// 邮箱是否已验证
+	EmailVerified bool `json:"email_verified"`
+	// 认证方式及最近一次强认证时间
+	AMR      []string         `json:"amr,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	// 模拟登录时为发起模拟的管理员
+	Actor *Actor `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
@@ -5,7 +5,9 @@ import (
 )
 
 type Util interface {
-	GenerateToken(userID int, username string, role string, extraExpiration int64) (string, error)
+	// GenerateToken 补全 iss/sub/iat/nbf/jti，未设置 exp 时使用默认有效期
+	GenerateToken(claims *Claims) (string, error)
 	ValidateToken(tokenString string) (*jwt.Token, error)
-	ExtractClaims(token *jwt.Token) (jwt.MapClaims, error)
+	ExtractClaims(token *jwt.Token) (*Claims, error)
+	JWKS() JWKSet
 }
@@ -0,0 +1,142 @@
+package jwt_util
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey 当前用于签发的密钥
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    interface{} // []byte / *rsa.PrivateKey / ed25519.PrivateKey
+}
+
+// verifyKey 可用于验签的密钥，轮换期间新旧密钥同时存在
+type verifyKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    interface{} // []byte / *rsa.PublicKey / ed25519.PublicKey
+}
+
+// JWK RFC 7517 公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet "/.well-known/jwks.json" 响应体
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// loadSigningKey 根据算法加载签名密钥及其对应公钥
+func loadSigningKey(alg, kid, secret, privateKeyFile string) (*signingKey, *verifyKey, error) {
+	switch alg {
+	case "", "HS256":
+		if secret == "" {
+			return nil, nil, errors.New("JWT_SECRET is required for HS256")
+		}
+		key := []byte(secret)
+		return &signingKey{kid: kid, method: jwt.SigningMethodHS256, key: key},
+			&verifyKey{kid: kid, method: jwt.SigningMethodHS256, key: key}, nil
+	case "RS256":
+		data, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read private key: %w", err)
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse RSA private key: %w", err)
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, key: key},
+			&verifyKey{kid: kid, method: jwt.SigningMethodRS256, key: &key.PublicKey}, nil
+	case "EdDSA":
+		data, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read private key: %w", err)
+		}
+		key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse Ed25519 private key: %w", err)
+		}
+		private, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, errors.New("private key is not Ed25519")
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodEdDSA, key: private},
+			&verifyKey{kid: kid, method: jwt.SigningMethodEdDSA, key: private.Public()}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+}
+
+// loadVerifyKeys 解析 "kid=path,kid=path" 形式的额外公钥列表
+func loadVerifyKeys(spec string) ([]*verifyKey, error) {
+	var keys []*verifyKey
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(item, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid verify key entry %q", item)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read public key %s: %w", kid, err)
+		}
+		if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			keys = append(keys, &verifyKey{kid: kid, method: jwt.SigningMethodRS256, key: key})
+			continue
+		}
+		if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+			keys = append(keys, &verifyKey{kid: kid, method: jwt.SigningMethodEdDSA, key: key})
+			continue
+		}
+		return nil, fmt.Errorf("unsupported public key %s", kid)
+	}
+	return keys, nil
+}
+
+// toJWK 对称密钥不对外发布
+func (k *verifyKey) toJWK() (JWK, bool) {
+	switch key := k.key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.kid,
+			Alg: k.method.Alg(),
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.kid,
+			Alg: k.method.Alg(),
+			Use: "sig",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
@@ -3,40 +3,77 @@ package jwt_util
 import (
 	"GoGin/internal/config"
 	"GoGin/internal/model"
+	utils "GoGin/internal/util"
+	"log"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type defaultJWTUtil struct {
-	config model.Config
+	config     model.Config
+	signing    *signingKey
+	verifyKeys map[string]*verifyKey
 }
 
 func NewJWTUtil(config *config.Config) Util {
+	kid := config.JWTKeyID
+	if kid == "" {
+		kid = "default"
+	}
+	signing, current, err := loadSigningKey(config.JWTAlgorithm, kid, config.JWTSecret, config.JWTPrivateKeyFile)
+	if err != nil {
+		log.Fatal("Failed to load JWT signing key:", err)
+	}
+	extra, err := loadVerifyKeys(config.JWTVerifyKeys)
+	if err != nil {
+		log.Fatal("Failed to load JWT verify keys:", err)
+	}
+
+	verifyKeys := map[string]*verifyKey{current.kid: current}
+	for _, key := range extra {
+		if _, ok := verifyKeys[key.kid]; ok {
+			log.Fatal("Duplicate JWT key id:", key.kid)
+		}
+		verifyKeys[key.kid] = key
+	}
+
 	return &defaultJWTUtil{
 		config: model.Config{
 			Issuer:         config.JWTIssuer,
 			SecretKey:      config.JWTSecret,
 			ExpirationTime: time.Duration(config.JWTExpireHours) * time.Hour,
 		},
+		signing:    signing,
+		verifyKeys: verifyKeys,
 	}
 }
 
-func (util *defaultJWTUtil) GenerateToken(userID int, username string, role string, extraExpiration int64) (string, error) {
-	claims := jwt.MapClaims{
-		"role":     role,
-		"username": username,
-		"user_id":  userID,
-		"iss":      util.config.Issuer,
-		"sub":      userID,
-		"iat":      time.Now().Unix(),
-		"nbf":      time.Now().Unix(),
-		"exp":      time.Now().Add(util.config.ExpirationTime).Unix() * extraExpiration,
+func (util *defaultJWTUtil) GenerateToken(claims *Claims) (string, error) {
+	jti, err := utils.RandomID(16)
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	now := time.Now()
+	if claims.TokenType == "" {
+		claims.TokenType = TokenTypeAccess
+	}
+	claims.ID = jti
+	claims.Issuer = util.config.Issuer
+	claims.Subject = strconv.Itoa(claims.UserID)
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(util.config.ExpirationTime))
+	}
+
+	token := jwt.NewWithClaims(util.signing.method, claims)
+	token.Header["kid"] = util.signing.kid
 
-	tokenString, err := token.SignedString([]byte(util.config.SecretKey))
+	tokenString, err := token.SignedString(util.signing.key)
 	if err != nil {
 		return "", err
 	}
@@ -45,12 +82,23 @@ func (util *defaultJWTUtil) GenerateToken(userID int, username string, role stri
 }
 
 func (util *defaultJWTUtil) ValidateToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		// 未带 kid 的旧令牌按当前签名密钥验证
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = util.signing.kid
+		}
+
+		key, ok := util.verifyKeys[kid]
+		if !ok {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+		// 算法必须与密钥匹配，防止算法混淆攻击
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, jwt.ErrSignatureInvalid
 		}
 
-		return []byte(util.config.SecretKey), nil
+		return key.key, nil
 	})
 
 	if err != nil {
@@ -60,10 +108,21 @@ func (util *defaultJWTUtil) ValidateToken(tokenString string) (*jwt.Token, error
 	return token, nil
 }
 
-func (util *defaultJWTUtil) ExtractClaims(token *jwt.Token) (jwt.MapClaims, error) {
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+func (util *defaultJWTUtil) ExtractClaims(token *jwt.Token) (*Claims, error) {
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		return claims, nil
 	} else {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
 }
+
+func (util *defaultJWTUtil) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range util.verifyKeys {
+		if jwk, ok := key.toJWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
@@ -0,0 +1,176 @@
+package password_policy
+
+import (
+	"GoGin/internal/config"
+	"GoGin/internal/util/hasher"
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// bcryptMaxBytes bcrypt 只使用前 72 字节，超出部分会被静默截断
+const bcryptMaxBytes = 72
+
+// defaultMaxBytes 未配置最大长度时的上限，避免超长密码占用哈希计算
+const defaultMaxBytes = 256
+
+// 规则名称，随违规项一起返回给客户端
+const (
+	RuleMinLength        = "min_length"
+	RuleMaxLength        = "max_length"
+	RuleRequireUpper     = "require_upper"
+	RuleRequireLower     = "require_lower"
+	RuleRequireDigit     = "require_digit"
+	RuleRequireSymbol    = "require_symbol"
+	RuleSymbolsForbidden = "symbols_not_allowed"
+	RuleInvalidCharacter = "invalid_character"
+	RuleCommonPassword   = "common_password"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = loadCommonPasswords(commonPasswordList)
+
+// Violation 未通过的单条规则
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PolicyError 密码未通过策略校验，包含全部违规项
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return "password does not meet policy: " + strings.Join(messages, "; ")
+}
+
+type Policy struct {
+	minLength     int
+	maxLength     int
+	requireUpper  bool
+	requireLower  bool
+	requireDigit  bool
+	requireSymbol bool
+	allowSymbols  bool
+	rejectCommon  bool
+}
+
+// NewPolicy hashAlgorithm 为新密码使用的哈希算法，bcrypt 时最大长度不超过 72 字节
+func NewPolicy(cfg config.PasswordPolicyConfig, hashAlgorithm string) *Policy {
+	maxLength := cfg.MaxLength
+	if maxLength <= 0 {
+		maxLength = defaultMaxBytes
+	}
+	if strings.EqualFold(hashAlgorithm, hasher.AlgorithmBcrypt) && maxLength > bcryptMaxBytes {
+		maxLength = bcryptMaxBytes
+	}
+	minLength := cfg.MinLength
+	if minLength < 1 {
+		minLength = 1
+	}
+	return &Policy{
+		minLength:     minLength,
+		maxLength:     maxLength,
+		requireUpper:  cfg.RequireUpper,
+		requireLower:  cfg.RequireLower,
+		requireDigit:  cfg.RequireDigit,
+		requireSymbol: cfg.RequireSymbol,
+		// 要求符号时必然允许符号
+		allowSymbols: cfg.AllowSymbols || cfg.RequireSymbol,
+		rejectCommon: cfg.RejectCommon,
+	}
+}
+
+// Validate 逐条检查所有规则，返回 *PolicyError 或 nil
+func (p *Policy) Validate(password string) error {
+	var violations []Violation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !utf8.ValidString(password) {
+		add(RuleInvalidCharacter, "password must be valid UTF-8")
+		return &PolicyError{Violations: violations}
+	}
+
+	if n := utf8.RuneCountInString(password); n < p.minLength {
+		add(RuleMinLength, "password must be at least %d characters", p.minLength)
+	}
+	if len(password) > p.maxLength {
+		add(RuleMaxLength, "password must be at most %d bytes", p.maxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol, hasInvalid bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsLetter(r):
+			// 无大小写之分的文字
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || r == ' ':
+			hasSymbol = true
+		default:
+			hasInvalid = true
+		}
+	}
+
+	if p.requireUpper && !hasUpper {
+		add(RuleRequireUpper, "password must contain an uppercase letter")
+	}
+	if p.requireLower && !hasLower {
+		add(RuleRequireLower, "password must contain a lowercase letter")
+	}
+	if p.requireDigit && !hasDigit {
+		add(RuleRequireDigit, "password must contain a digit")
+	}
+	if p.requireSymbol && !hasSymbol {
+		add(RuleRequireSymbol, "password must contain a symbol")
+	}
+	if !p.allowSymbols && hasSymbol {
+		add(RuleSymbolsForbidden, "password must not contain symbols")
+	}
+	if hasInvalid {
+		add(RuleInvalidCharacter, "password must not contain control or whitespace characters other than space")
+	}
+	if p.rejectCommon && IsCommon(password) {
+		add(RuleCommonPassword, "password is too common")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// IsCommon 忽略大小写比较内置的常见密码列表
+func IsCommon(password string) bool {
+	_, ok := commonPasswords[strings.ToLower(password)]
+	return ok
+}
+
+func loadCommonPasswords(list string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
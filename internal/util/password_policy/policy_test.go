@@ -0,0 +1,81 @@
+package password_policy
+
+import (
+	"GoGin/internal/config"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func rules(err error) []string {
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) {
+		return nil
+	}
+	names := make([]string, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		names = append(names, v.Rule)
+	}
+	return names
+}
+
+func TestValidate(t *testing.T) {
+	strict := NewPolicy(config.PasswordPolicyConfig{
+		MinLength:     10,
+		MaxLength:     64,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		RejectCommon:  true,
+	}, "argon2id")
+	noSymbols := NewPolicy(config.PasswordPolicyConfig{MinLength: 8, AllowSymbols: false}, "argon2id")
+
+	tests := []struct {
+		name     string
+		policy   *Policy
+		password string
+		want     []string
+	}{
+		{"valid", strict, "Correct-Horse-42", nil},
+		{"too short", strict, "Ab1!", []string{RuleMinLength}},
+		{"too long", strict, "Aa1!" + strings.Repeat("x", 61), []string{RuleMaxLength}},
+		{"missing classes", strict, "alllowercaseletters", []string{RuleRequireUpper, RuleRequireDigit, RuleRequireSymbol}},
+		{"common", NewPolicy(config.PasswordPolicyConfig{MinLength: 1, RejectCommon: true}, ""), "PASSWORD", []string{RuleCommonPassword}},
+		{"symbols forbidden", noSymbols, "abc def-ghi", []string{RuleSymbolsForbidden}},
+		{"require symbol implies allow", NewPolicy(config.PasswordPolicyConfig{RequireSymbol: true}, ""), "a!", nil},
+		{"control character", noSymbols, "abcdefgh\t", []string{RuleInvalidCharacter}},
+		{"invalid utf8", strict, "abc\xffdefghij", []string{RuleInvalidCharacter}},
+		// 长度按字符计数，中文密码不会因为字节数多而被误判
+		{"min length counts runes", NewPolicy(config.PasswordPolicyConfig{MinLength: 4}, ""), "正确密码", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(tt.policy.Validate(tt.password))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("Validate(%q) rules = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+// bcrypt 只使用前 72 字节，首选 bcrypt 时最大长度不能超过它；argon2id 不受限制
+func TestMaxLengthCappedAtBcryptLimit(t *testing.T) {
+	cfg := config.PasswordPolicyConfig{MinLength: 1, MaxLength: 200, AllowSymbols: true}
+	long := strings.Repeat("a", bcryptMaxBytes+1)
+
+	p := NewPolicy(cfg, "bcrypt")
+	if got := rules(p.Validate(strings.Repeat("a", bcryptMaxBytes))); got != nil {
+		t.Fatalf("72-byte password rules = %v, want none", got)
+	}
+	if got := rules(p.Validate(long)); strings.Join(got, ",") != RuleMaxLength {
+		t.Fatalf("73-byte password rules = %v, want [%s]", got, RuleMaxLength)
+	}
+
+	if got := rules(NewPolicy(cfg, "argon2id").Validate(long)); got != nil {
+		t.Fatalf("73-byte password with argon2id rules = %v, want none", got)
+	}
+	if got := rules(NewPolicy(config.PasswordPolicyConfig{MinLength: 1}, "argon2id").Validate(strings.Repeat("a", defaultMaxBytes+1))); strings.Join(got, ",") != RuleMaxLength {
+		t.Fatalf("password over the default limit rules = %v, want [%s]", got, RuleMaxLength)
+	}
+}
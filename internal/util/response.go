@@ -24,3 +24,12 @@ func Error(c *gin.Context, errCode int, msg string) {
 		"data":    nil,
 	})
 }
+
+// ErrorWithData 错误响应附带结构化详情
+func ErrorWithData(c *gin.Context, errCode int, msg string, data interface{}) {
+	c.JSON(errCode, gin.H{
+		"status":  errCode,
+		"message": msg,
+		"data":    data,
+	})
+}
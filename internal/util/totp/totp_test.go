@@ -0,0 +1,86 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 的 SHA1 测试密钥 "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// 附录 B 给出的是 8 位验证码，6 位取其后 6 位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	// 密钥允许小写和首尾空白
+	got, err := Code(" "+strings.ToLower(rfcSecret)+" ", 1)
+	if err != nil || got != "287082" {
+		t.Fatalf("Code with lowercase secret = %q, %v", got, err)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Fatal("Code accepted an invalid secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := Step(now)
+	previous, _ := Code(rfcSecret, current-1)
+
+	if step, ok := Validate(rfcSecret, "081804", now, 0); !ok || step != current {
+		t.Fatalf("Validate(current) = %d, %v", step, ok)
+	}
+	if _, ok := Validate(rfcSecret, previous, now, 0); ok {
+		t.Fatal("Validate accepted the previous step without skew")
+	}
+	if step, ok := Validate(rfcSecret, previous, now, 1); !ok || step != current-1 {
+		t.Fatalf("Validate(previous, skew 1) = %d, %v", step, ok)
+	}
+	for _, code := range []string{"", "12345", "1234567", "000000"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Fatalf("Validate accepted %q", code)
+		}
+	}
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Fatalf("secret length = %d, want 32", len(secret))
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Fatalf("generated secret not usable: %v", err)
+	}
+
+	u, err := url.Parse(URI("Demo", "alice@school.edu", secret))
+	if err != nil {
+		t.Fatalf("parse URI: %v", err)
+	}
+	query := u.Query()
+	if u.Scheme != "otpauth" || u.Host != "totp" || query.Get("secret") != secret || query.Get("issuer") != "Demo" || query.Get("digits") != "6" {
+		t.Fatalf("URI = %s", u)
+	}
+}